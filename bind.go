@@ -0,0 +1,215 @@
+package goenv
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Bind fills the struct pointed to by v from the Default instance, see
+// Config.Bind
+func Bind(v any) error {
+	return defaultConfig.Bind(v)
+}
+
+// Bind fills the struct pointed to by v from c. Fields are selected with the
+// env tag and converted with the rules of GetEnv:
+//
+//	type Config struct {
+//		Port    int           `env:"PORT" default:"8080"`
+//		Timeout time.Duration `env:"TIMEOUT" default:"30s"`
+//		DB      struct {
+//			Host string `env:"host" required:"true"`
+//		} `env:"db"`
+//	}
+//
+// The env tag of a nested struct is a prefix joined with a dot, matching the
+// keys produced by JSON and YAML files, so DB.Host above is read from db.host.
+// Embedded structs without a tag share the prefix of their parent. A nil
+// pointer to a nested struct is only allocated when one of its keys is set,
+// and a struct type nested in itself is not bound again. Fields without an
+// env tag, or tagged env:"-", are skipped.
+//
+// An unset field takes the value of its default tag, is reported when tagged
+// required:"true", and is left unchanged otherwise.
+//
+// Values are then checked against the comma-separated rules of the validate
+// tag: min and max (value for numbers and durations, length for strings,
+// slices and maps), oneof=a b c, regexp=pattern (must be the last rule), url,
+// hostname_port, nonempty, and required_if=KEY value, which makes the field
+// required when the sibling key KEY has the given value. Unknown rules and
+// invalid arguments are reported even when the key is unset. A struct that
+// implements Validator is validated after its fields were set.
+//
+// Slice and map fields accept JSON or delimited values; the sep tag overrides
+// the separator set with SetListSeparator for a single field. Pointer fields
+// such as *int stay nil when their key is unset and has no default.
+//
+// Bind sets every field it can and returns a single error listing every
+// missing, invalid or rejected field, each with the origin of its value.
+func (c *Config) Bind(v any) error {
+	ptr := reflect.ValueOf(v)
+	if ptr.Kind() != reflect.Pointer || ptr.IsNil() || ptr.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("bind: expected a non-nil pointer to a struct, got %T", v)
+	}
+
+	var errs []error
+	c.bindStruct(ptr.Elem(), "", []reflect.Type{ptr.Elem().Type()}, &errs)
+	return errors.Join(errs...)
+}
+
+// bindStruct fills the fields of a struct value, appending field errors to
+// errs. stack holds the struct types being bound, to stop at cycles.
+func (c *Config) bindStruct(v reflect.Value, prefix string, stack []reflect.Type, errs *[]error) {
+	failed := len(*errs)
+
+	typ := v.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, tagged := field.Tag.Lookup("env")
+		if name == "-" || (!tagged && !field.Anonymous) {
+			continue
+		}
+
+		value := v.Field(i)
+		if nestedType, ok := nestedStruct(field.Type); ok {
+			nestedPrefix := prefix
+			if tagged {
+				nestedPrefix += name + "."
+			}
+			if slices.Contains(stack, nestedType) {
+				continue
+			}
+			if value.Kind() == reflect.Pointer {
+				if value.IsNil() {
+					if !c.anySet(nestedType, nestedPrefix, stack) {
+						continue
+					}
+					value.Set(reflect.New(nestedType))
+				}
+				value = value.Elem()
+			}
+			c.bindStruct(value, nestedPrefix, append(stack, nestedType), errs)
+			continue
+		}
+		if !tagged {
+			continue
+		}
+
+		if err := c.bindField(value, field, prefix, name); err != nil {
+			*errs = append(*errs, err)
+		}
+	}
+
+	if len(*errs) > failed {
+		return
+	}
+	if validator, ok := v.Addr().Interface().(Validator); ok {
+		if err := validator.Validate(); err != nil {
+			if prefix != "" {
+				err = fmt.Errorf("%s: %w", strings.TrimSuffix(prefix, "."), err)
+			}
+			*errs = append(*errs, err)
+		}
+	}
+}
+
+// bindField sets a single field from the key name under prefix and validates
+// its value
+func (c *Config) bindField(value reflect.Value, field reflect.StructField, prefix, name string) error {
+	key := prefix + name
+	// Pointers to values such as *int tell an unset key apart from zero; they
+	// stay nil when unset and point to the parsed value otherwise
+	typ := field.Type
+	pointer := typ.Kind() == reflect.Pointer && !canParse(typ) && canParse(typ.Elem())
+	if pointer {
+		typ = typ.Elem()
+	}
+
+	rules := parseRules(field.Tag.Get("validate"))
+	if err := checkRuleTag(rules, typ); err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+
+	raw, err := c.lookup(key)
+	if err != nil {
+		return err
+	}
+	origin := c.Origin(key)
+	if current, _ := c.Lookup(key); raw != "" && current == "" {
+		origin = key + "_FILE"
+	}
+
+	if raw == "" {
+		def, ok := field.Tag.Lookup("default")
+		if ok {
+			raw, origin = def, "default"
+		} else if field.Tag.Get("required") == "true" {
+			return fmt.Errorf("%s: required but not set", key)
+		} else if reason, ok := c.requiredIf(rules, prefix); ok {
+			return fmt.Errorf("%s: required when %s but not set", key, reason)
+		} else {
+			return validateField(value, rules, key, origin, false)
+		}
+	}
+
+	sep := field.Tag.Get("sep")
+	if sep == "" {
+		sep = currentListSeparator()
+	}
+	parsed, err := parseKeySep(key, raw, typ, sep)
+	if errors.Is(err, errUnsupportedType) {
+		return fmt.Errorf("%s: unsupported field type %s", key, field.Type)
+	} else if err != nil {
+		return err
+	}
+	if pointer {
+		value.Set(reflect.New(typ))
+		value = value.Elem()
+	}
+	value.Set(parsed)
+	return validateField(value, rules, key, origin, true)
+}
+
+// anySet reports whether a key of a field of the struct type typ, or of its
+// nested structs, is set under prefix
+func (c *Config) anySet(typ reflect.Type, prefix string, stack []reflect.Type) bool {
+	stack = append(stack, typ)
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		name, tagged := field.Tag.Lookup("env")
+		if !field.IsExported() || name == "-" || (!tagged && !field.Anonymous) {
+			continue
+		}
+
+		key := prefix + name
+		if nestedType, ok := nestedStruct(field.Type); ok {
+			if tagged {
+				key += "."
+			}
+			if !slices.Contains(stack, nestedType) && c.anySet(nestedType, key, stack) {
+				return true
+			}
+		} else if raw, _ := c.lookup(key); tagged && raw != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// nestedStruct returns the struct type that a field of type typ holds or
+// points to, when the field is not parsed as a single value
+func nestedStruct(typ reflect.Type) (reflect.Type, bool) {
+	if typ.Kind() == reflect.Pointer && typ.Elem().Kind() == reflect.Struct && !canParse(typ) && !canParse(typ.Elem()) {
+		return typ.Elem(), true
+	}
+	if typ.Kind() == reflect.Struct && !canParse(typ) {
+		return typ, true
+	}
+	return nil, false
+}
@@ -0,0 +1,197 @@
+package goenv
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBind(t *testing.T) {
+	tmpFile := createTempFile(t, ".yaml", "name: api\ndb:\n  host: db.internal\n  port: 5432\n  pool:\n    size: 20\ncache:\n  ttl: 1m\n")
+	defer os.Remove(tmpFile)
+
+	c := NewConfig()
+	if err := c.Load(tmpFile); err != nil {
+		t.Fatal(err)
+	}
+
+	type Pool struct {
+		Size int `env:"size" default:"10"`
+	}
+	type Extra struct {
+		Ratio float64 `env:"ratio" default:"0.5"`
+	}
+	var cfg struct {
+		Name    string        `env:"name" required:"true"`
+		Port    int           `env:"port" default:"8080"`
+		Timeout time.Duration `env:"timeout" default:"30s"`
+		Debug   bool          `env:"debug"`
+		Ignored string
+		Skipped string `env:"-"`
+		DB      struct {
+			Host string `env:"host"`
+			Port int64  `env:"port"`
+			Pool Pool   `env:"pool"`
+		} `env:"db"`
+		Cache *struct {
+			TTL time.Duration `env:"ttl"`
+		} `env:"cache"`
+		Extra
+		Unbound struct {
+			Ratio float64 `env:"ratio" default:"0.5"`
+		}
+	}
+	cfg.Debug = true
+	cfg.Ignored = "kept"
+
+	if err := c.Bind(&cfg); err != nil {
+		t.Fatalf("Bind() error = %v", err)
+	}
+
+	if cfg.Name != "api" || cfg.Port != 8080 || cfg.Timeout != 30*time.Second {
+		t.Errorf("top-level fields = %q %d %v", cfg.Name, cfg.Port, cfg.Timeout)
+	}
+	if !cfg.Debug || cfg.Ignored != "kept" {
+		t.Errorf("Bind() modified fields without a value: Debug=%v Ignored=%q", cfg.Debug, cfg.Ignored)
+	}
+	if cfg.DB.Host != "db.internal" || cfg.DB.Port != 5432 || cfg.DB.Pool.Size != 20 {
+		t.Errorf("DB = %+v", cfg.DB)
+	}
+	if cfg.Cache == nil || cfg.Cache.TTL != time.Minute {
+		t.Errorf("Cache = %+v", cfg.Cache)
+	}
+	if cfg.Ratio != 0.5 {
+		t.Errorf("Extra.Ratio = %v, want 0.5", cfg.Ratio)
+	}
+	if cfg.Unbound.Ratio != 0 {
+		t.Errorf("Bind() filled the untagged struct field Unbound: %+v", cfg.Unbound)
+	}
+}
+
+type bindNode struct {
+	Name string    `env:"NAME"`
+	Next *bindNode `env:"next"`
+	Prev *bindNode
+}
+
+func TestBind_NestedPointers(t *testing.T) {
+	c := NewConfig()
+	c.Set("NAME", "head")
+	c.Set("next.NAME", "tail")
+
+	var node bindNode
+	if err := c.Bind(&node); err != nil {
+		t.Fatalf("Bind() error = %v", err)
+	}
+	if node.Name != "head" || node.Next != nil || node.Prev != nil {
+		t.Errorf("Bind() of a self-referential type = %+v", node)
+	}
+
+	type Client struct {
+		Timeout time.Duration `env:"timeout"`
+	}
+	var cfg struct {
+		HTTP   *Client
+		Cache  *Client `env:"cache"`
+		Remote *Client `env:"remote"`
+	}
+	c.Set("remote.timeout", "5s")
+	if err := c.Bind(&cfg); err != nil {
+		t.Fatalf("Bind() error = %v", err)
+	}
+	if cfg.HTTP != nil {
+		t.Errorf("Bind() allocated the untagged field HTTP: %+v", cfg.HTTP)
+	}
+	if cfg.Cache != nil {
+		t.Errorf("Bind() allocated Cache without any key set: %+v", cfg.Cache)
+	}
+	if cfg.Remote == nil || cfg.Remote.Timeout != 5*time.Second {
+		t.Errorf("Remote = %+v, want a timeout of 5s", cfg.Remote)
+	}
+}
+
+func TestBind_PointerFields(t *testing.T) {
+	c := NewConfig()
+	c.Set("PORT", "0")
+	c.Set("TIMEOUT", "5s")
+
+	var cfg struct {
+		Port    *int           `env:"PORT" validate:"max=65535"`
+		Timeout *time.Duration `env:"TIMEOUT"`
+		Name    *string        `env:"NAME"`
+		Level   *string        `env:"LEVEL" default:"info"`
+	}
+	if err := c.Bind(&cfg); err != nil {
+		t.Fatalf("Bind() error = %v", err)
+	}
+	if cfg.Port == nil || *cfg.Port != 0 {
+		t.Errorf("Port = %v, want a pointer to 0", cfg.Port)
+	}
+	if cfg.Timeout == nil || *cfg.Timeout != 5*time.Second {
+		t.Errorf("Timeout = %v, want a pointer to 5s", cfg.Timeout)
+	}
+	if cfg.Name != nil {
+		t.Errorf("Name = %q, want nil", *cfg.Name)
+	}
+	if cfg.Level == nil || *cfg.Level != "info" {
+		t.Errorf("Level = %v, want a pointer to the default info", cfg.Level)
+	}
+
+	c.Set("PORT", "70000")
+	if err := c.Bind(&cfg); err == nil || !strings.Contains(err.Error(), "at most 65535") {
+		t.Errorf("Bind() error = %v, want the max rule applied to *int", err)
+	}
+}
+
+func TestBind_Errors(t *testing.T) {
+	c := NewConfig()
+	c.Set("PORT", "80a")
+	c.Set("TIMEOUT", "soon")
+	c.Set("OK", "1")
+
+	var cfg struct {
+		Port     int           `env:"PORT"`
+		Timeout  time.Duration `env:"TIMEOUT"`
+		Token    string        `env:"TOKEN" required:"true"`
+		Workers  int           `env:"WORKERS" default:"many"`
+		Channels chan int      `env:"OK"`
+		Secret   string        `env:"SECRET" required:"true"`
+	}
+	err := c.Bind(&cfg)
+	if err == nil {
+		t.Fatal("Bind() expected an error")
+	}
+
+	for _, want := range []string{
+		`PORT: invalid value "80a" for int`,
+		`TIMEOUT: invalid value "soon" for time.Duration`,
+		"TOKEN: required but not set",
+		`WORKERS: invalid value "many" for int`,
+		"OK: unsupported field type chan int",
+		"SECRET: required but not set",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not contain %q", err, want)
+		}
+	}
+
+	if err := c.Bind(cfg); err == nil {
+		t.Error("Bind() with a non-pointer expected an error")
+	}
+}
+
+func TestBind_Default(t *testing.T) {
+	os.Setenv("BIND_DEFAULT_PORT", "9090")
+	defer os.Unsetenv("BIND_DEFAULT_PORT")
+
+	var cfg struct {
+		Port int `env:"BIND_DEFAULT_PORT"`
+	}
+	if err := Bind(&cfg); err != nil {
+		t.Fatalf("Bind() error = %v", err)
+	}
+	if cfg.Port != 9090 {
+		t.Errorf("Port = %d, want 9090", cfg.Port)
+	}
+}
@@ -0,0 +1,581 @@
+package goenv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Config is a set of configuration values with the same Load and Get surface
+// as the package functions. A Config created with NewConfig keeps its values
+// in its own map and never modifies the process environment, so several
+// components or parallel tests can each hold a different configuration.
+//
+// The package functions such as LoadEnv and GetEnv operate on the Default
+// instance, which stores its values in the process environment.
+type Config struct {
+	mu       sync.RWMutex
+	values   map[string]string
+	process  bool // values are stored in the process environment
+	fallback bool // missing keys are looked up in the process environment
+
+	secretFiles *SecretFileOptions
+
+	subscribers []subscriber
+	nextID      int
+
+	history []loadCall        // successful LoadWithFormat calls, replayed by Reload
+	loaded  map[string]string // values loaded by the calls in history
+	origins map[string]string // names of the sources that provided each key
+}
+
+// loadCall is a recorded LoadWithFormat call. Its sources are kept so that
+// Reload reuses them, e.g. to revalidate HTTP documents with their ETag.
+type loadCall struct {
+	format  FileFormat
+	files   []string
+	sources []Source
+}
+
+// subscriber is a change callback registered with Subscribe
+type subscriber struct {
+	id int
+	fn func(Diff)
+}
+
+// ConfigOption configures a Config created with NewConfig
+type ConfigOption func(*Config)
+
+// WithEnvFallback makes the Config look up keys it does not hold in the
+// process environment
+func WithEnvFallback() ConfigOption {
+	return func(c *Config) {
+		c.fallback = true
+	}
+}
+
+// NewConfig creates an empty, isolated Config. It is safe for concurrent use.
+func NewConfig(opts ...ConfigOption) *Config {
+	c := &Config{values: make(map[string]string)}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+var defaultConfig = &Config{process: true}
+
+// Default returns the Config used by the package functions. Its values are
+// stored in the process environment.
+func Default() *Config {
+	return defaultConfig
+}
+
+// Load loads values from the first file that loads successfully, like LoadEnv
+func (c *Config) Load(file ...string) error {
+	return c.LoadWithFormat(FormatAuto, file...)
+}
+
+// LoadWithFormat loads values from files with the specified format, like
+// LoadEnvWithFormat
+func (c *Config) LoadWithFormat(format FileFormat, file ...string) error {
+	call := loadCall{format: format, files: append([]string(nil), file...)}
+	c.mu.RLock()
+	i := c.findCall(call)
+	if i >= 0 {
+		call.sources = c.history[i].sources
+	}
+	c.mu.RUnlock()
+	if call.sources == nil {
+		call.sources = fileSources(format, file)
+	}
+
+	values, origin, err := loadFirst(c.loadContext(context.Background()), call.sources)
+	if err != nil {
+		return fmt.Errorf("failed to load any of the specified files: %w", err)
+	}
+
+	c.mu.Lock()
+	// A repeated call moves to the end, where its values now take precedence
+	if i := c.findCall(call); i >= 0 {
+		c.history = append(c.history[:i:i], c.history[i+1:]...)
+	}
+	c.history = append(c.history, call)
+	if c.loaded == nil {
+		c.loaded = make(map[string]string)
+	}
+	for key, value := range values {
+		c.loaded[key] = value
+	}
+	c.mu.Unlock()
+
+	c.apply(values, origin)
+	return nil
+}
+
+// Reload re-runs every successful Load and LoadWithFormat call in the original
+// order and atomically applies the result, removing keys that are no longer
+// present in the files. Keys that a later LoadSources call took over, such as
+// secrets loaded from Vault, keep their value. If any call fails, nothing is
+// changed.
+func (c *Config) Reload(ctx context.Context) (Diff, error) {
+	c.mu.RLock()
+	history := append([]loadCall(nil), c.history...)
+	prev := copyValues(c.loaded)
+	c.mu.RUnlock()
+
+	next := make(map[string]string)
+	origins := make(map[string]string)
+	files := make(map[string]bool)
+	for _, call := range history {
+		values, origin, err := loadFirst(c.loadContext(ctx), call.sources)
+		if err != nil {
+			return Diff{}, fmt.Errorf("failed to reload %s: %w", strings.Join(call.files, ", "), err)
+		}
+		for key, value := range values {
+			next[key] = value
+			origins[key] = origin
+		}
+		for i, source := range call.sources {
+			files[sourceName(source, i)] = true
+		}
+	}
+
+	c.mu.Lock()
+	c.loaded = copyValues(next)
+	// Leave keys whose current value came from another source alone
+	for key, origin := range c.origins {
+		if !files[origin] {
+			delete(prev, key)
+			delete(next, key)
+		}
+	}
+	c.mu.Unlock()
+	return c.replace(prev, next, origins), nil
+}
+
+// Checkpoint saves the load history that Reload replays, together with the
+// origins of the loaded keys, and returns a function that restores them.
+// Loads made after the checkpoint are forgotten; the values they set are not
+// changed, so test helpers that restore the environment, such as
+// goenvtest.Snapshot, call both.
+func (c *Config) Checkpoint() (restore func()) {
+	c.mu.RLock()
+	history := append([]loadCall(nil), c.history...)
+	loaded := copyValues(c.loaded)
+	origins := copyValues(c.origins)
+	c.mu.RUnlock()
+
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.history = history
+		c.loaded = loaded
+		c.origins = origins
+	}
+}
+
+// LoadSources loads values from the first source that loads successfully,
+// like the package function LoadSources
+func (c *Config) LoadSources(ctx context.Context, sources ...Source) error {
+	if err := c.loadFirst(ctx, sources); err != nil {
+		return fmt.Errorf("failed to load any of the specified sources: %w", err)
+	}
+	return nil
+}
+
+// Lookup returns the raw value of key and whether it is set
+func (c *Config) Lookup(key string) (string, bool) {
+	c.mu.RLock()
+	value, ok := c.lookupLocked(key)
+	c.mu.RUnlock()
+	if !ok && c.fallback {
+		return os.LookupEnv(key)
+	}
+	return value, ok
+}
+
+// Set sets the value of key
+func (c *Config) Set(key, value string) {
+	c.apply(map[string]string{key: value}, "")
+}
+
+// Unset removes key
+func (c *Config) Unset(key string) {
+	c.replace(map[string]string{key: ""}, nil, nil)
+}
+
+// Origin returns the name of the file or source that provided the value of
+// key, such as a file path or "vault:secret/myapp". Keys set otherwise are
+// reported as "environment" when they come from the process environment, and
+// as an empty string when the origin is unknown.
+func (c *Config) Origin(key string) string {
+	c.mu.Lock()
+	origin, ok := c.origins[key]
+	if _, set := c.lookupLocked(key); ok && set {
+		c.mu.Unlock()
+		return origin
+	} else if ok {
+		// The key was removed without c, e.g. with os.Unsetenv
+		delete(c.origins, key)
+	}
+	_, held := c.values[key]
+	c.mu.Unlock()
+
+	if c.process || c.fallback {
+		if _, ok := os.LookupEnv(key); ok && !held {
+			return "environment"
+		}
+	}
+	return ""
+}
+
+// Keys returns the sorted keys held by the Config. For the Default instance
+// these are all process environment variables.
+func (c *Config) Keys() []string {
+	var keys []string
+	if c.process {
+		for key := range processEnv() {
+			keys = append(keys, key)
+		}
+	} else {
+		c.mu.RLock()
+		for key := range c.values {
+			keys = append(keys, key)
+		}
+		c.mu.RUnlock()
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Subscribe registers fn to be called with the diff of every update that
+// changes at least one value, whether it comes from Load, Set, Unset or a
+// Watcher. Callbacks run synchronously in the goroutine that made the change,
+// after the change is visible. For the Default instance, changes made directly
+// through os.Setenv are not observed. The returned function unsubscribes fn.
+func (c *Config) Subscribe(fn func(Diff)) (cancel func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.nextID++
+	id := c.nextID
+	c.subscribers = append(c.subscribers, subscriber{id: id, fn: fn})
+
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		for i, sub := range c.subscribers {
+			if sub.id == id {
+				c.subscribers = append(c.subscribers[:i:i], c.subscribers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// EnableSecretFiles enables the _FILE suffix convention for this Config, see
+// the package function EnableSecretFiles
+func (c *Config) EnableSecretFiles(opts SecretFileOptions) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.secretFiles = &opts
+}
+
+// DisableSecretFiles disables the _FILE suffix convention for this Config
+func (c *Config) DisableSecretFiles() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.secretFiles = nil
+}
+
+// Diff describes how an update changed the values of a Config. Key lists are
+// sorted.
+type Diff struct {
+	Added   []string          // Keys that were not set before
+	Changed []string          // Keys whose value changed
+	Removed []string          // Keys that are no longer set
+	Old     map[string]string // Previous values of changed and removed keys
+	New     map[string]string // New values of added and changed keys
+}
+
+// Empty reports whether the update changed nothing
+func (d Diff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Changed) == 0 && len(d.Removed) == 0
+}
+
+// String returns a summary of the changed keys without their values, which
+// may be secret
+func (d Diff) String() string {
+	if d.Empty() {
+		return "no changes"
+	}
+
+	var parts []string
+	for _, group := range []struct {
+		name string
+		keys []string
+	}{{"added", d.Added}, {"changed", d.Changed}, {"removed", d.Removed}} {
+		if len(group.keys) > 0 {
+			parts = append(parts, group.name+": "+strings.Join(group.keys, ", "))
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Get retrieves a value from c with type conversion, like GetEnv
+func Get[T any](c *Config, key string, defaultVal T) T {
+	val, err := c.lookup(key)
+	if err != nil || val == "" {
+		return defaultVal
+	}
+	return convert(val, defaultVal)
+}
+
+// Lookup retrieves a value from c with type conversion, like LookupEnv
+func Lookup[T any](c *Config, key string) (T, bool, error) {
+	var zero T
+	val, err := c.lookup(key)
+	if err != nil {
+		return zero, true, err
+	}
+	if val == "" {
+		return zero, false, nil
+	}
+
+	parsed, err := parseKey(key, val, reflect.TypeOf(&zero).Elem())
+	if err != nil {
+		return zero, true, err
+	}
+	return parsed.Interface().(T), true, nil
+}
+
+// GetE retrieves a value from c with type conversion, like GetEnvE
+func GetE[T any](c *Config, key string, defaultVal T) (T, error) {
+	val, ok, err := Lookup[T](c, key)
+	if err != nil || !ok {
+		return defaultVal, err
+	}
+	return val, nil
+}
+
+// GetJSON decodes a JSON value from c, like GetEnvJSON
+func GetJSON[T any](c *Config, key string, defaultVal T) (T, error) {
+	val, err := c.lookup(key)
+	if err != nil || val == "" {
+		return defaultVal, err
+	}
+
+	var out T
+	if err := json.Unmarshal([]byte(val), &out); err != nil {
+		return defaultVal, &ParseError{Key: key, Value: val, Type: reflect.TypeOf(&out).Elem(), Err: err}
+	}
+	return out, nil
+}
+
+// OnChange calls fn when the value of key in the Default instance changes.
+// Values are converted like GetEnv, with the zero value of T for unset or
+// invalid values, and fn is only called when the converted value changes.
+// The returned function unsubscribes fn.
+func OnChange[T any](key string, fn func(old, new T)) (cancel func()) {
+	return OnConfigChange(defaultConfig, key, fn)
+}
+
+// OnConfigChange calls fn when the value of key in c changes, like OnChange
+func OnConfigChange[T any](c *Config, key string, fn func(old, new T)) (cancel func()) {
+	return c.Subscribe(func(d Diff) {
+		oldRaw, changed := d.Old[key]
+		newRaw, set := d.New[key]
+		if !changed && !set {
+			return
+		}
+
+		var zero T
+		oldVal, newVal := zero, zero
+		if oldRaw != "" {
+			oldVal = convert(oldRaw, zero)
+		}
+		if newRaw != "" {
+			newVal = convert(newRaw, zero)
+		}
+		if !reflect.DeepEqual(oldVal, newVal) {
+			fn(oldVal, newVal)
+		}
+	})
+}
+
+// lookup returns the value of key, falling back to the file named by KEY_FILE
+// when secret files are enabled. An empty value means unset.
+func (c *Config) lookup(key string) (string, error) {
+	if val, _ := c.Lookup(key); val != "" {
+		return val, nil
+	}
+
+	c.mu.RLock()
+	opts := c.secretFiles
+	c.mu.RUnlock()
+	if opts == nil {
+		return "", nil
+	}
+
+	path, _ := c.Lookup(key + "_FILE")
+	if path == "" {
+		return "", nil
+	}
+	val, err := readSecretFile(path, *opts)
+	if err != nil {
+		return "", fmt.Errorf("%s_FILE: %w", key, err)
+	}
+	return val, nil
+}
+
+// apply stores every key in values, recording origin as their source
+func (c *Config) apply(values map[string]string, origin string) Diff {
+	return c.replace(nil, values, originsOf(values, origin))
+}
+
+// originsOf maps every key in values to origin
+func originsOf(values map[string]string, origin string) map[string]string {
+	origins := make(map[string]string, len(values))
+	if origin != "" {
+		for key := range values {
+			origins[key] = origin
+		}
+	}
+	return origins
+}
+
+// replace atomically stores every key in next and removes the keys of prev
+// that next no longer holds, then notifies subscribers. Readers observe either
+// all or none of the changes. Keys set by other means are left alone unless
+// next overrides them. origins names the source of each key in next.
+func (c *Config) replace(prev, next, origins map[string]string) Diff {
+	diff, subscribers := c.replaceLocked(prev, next, origins)
+	if !diff.Empty() {
+		for _, sub := range subscribers {
+			sub.fn(diff)
+		}
+	}
+	return diff
+}
+
+// replaceLocked applies the changes of replace under the lock and returns the
+// subscribers to notify
+func (c *Config) replaceLocked(prev, next, origins map[string]string) (Diff, []subscriber) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.origins == nil {
+		c.origins = make(map[string]string)
+	}
+	diff := Diff{Old: make(map[string]string), New: make(map[string]string)}
+	for key, value := range next {
+		if origin := origins[key]; origin != "" {
+			c.origins[key] = origin
+		} else {
+			delete(c.origins, key)
+		}
+
+		old, ok := c.lookupLocked(key)
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, key)
+		case old != value:
+			diff.Changed = append(diff.Changed, key)
+			diff.Old[key] = old
+		default:
+			continue
+		}
+		diff.New[key] = value
+		c.setLocked(key, value)
+	}
+	for key := range prev {
+		if _, ok := next[key]; ok {
+			continue
+		}
+		delete(c.origins, key)
+		if old, ok := c.lookupLocked(key); ok {
+			diff.Removed = append(diff.Removed, key)
+			diff.Old[key] = old
+			c.unsetLocked(key)
+		}
+	}
+
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Changed)
+	sort.Strings(diff.Removed)
+	return diff, append([]subscriber(nil), c.subscribers...)
+}
+
+// lookupLocked returns the stored value of key. c.mu must be held.
+func (c *Config) lookupLocked(key string) (string, bool) {
+	if c.process {
+		return os.LookupEnv(key)
+	}
+	value, ok := c.values[key]
+	return value, ok
+}
+
+// setLocked stores the value of key. c.mu must be held for writing.
+func (c *Config) setLocked(key, value string) {
+	if c.process {
+		os.Setenv(key, value)
+	} else {
+		c.values[key] = value
+	}
+}
+
+// unsetLocked removes key. c.mu must be held for writing.
+func (c *Config) unsetLocked(key string) {
+	if c.process {
+		os.Unsetenv(key)
+	} else {
+		delete(c.values, key)
+	}
+}
+
+// loadFirst applies the values of the first source that loads successfully.
+// The returned error reports why each source failed.
+func (c *Config) loadFirst(ctx context.Context, sources []Source) error {
+	values, origin, err := loadFirst(c.loadContext(ctx), sources)
+	if err != nil {
+		return err
+	}
+	c.apply(values, origin)
+	return nil
+}
+
+// findCall returns the index of a recorded call with the same format and
+// files as call, or -1
+func (c *Config) findCall(call loadCall) int {
+	for i, recorded := range c.history {
+		if recorded.format == call.format && slices.Equal(recorded.files, call.files) {
+			return i
+		}
+	}
+	return -1
+}
+
+// loadContext returns a copy of ctx in which the sources loaded into c
+// resolve env: references with c.Lookup, so an isolated Config only sees the
+// process environment when it stores or falls back to it
+func (c *Config) loadContext(ctx context.Context) context.Context {
+	return withLookup(ctx, c.Lookup)
+}
+
+// processEnv returns the process environment as a map
+func processEnv() map[string]string {
+	env := make(map[string]string)
+	for _, kv := range os.Environ() {
+		if key, value, ok := strings.Cut(kv, "="); ok {
+			env[key] = value
+		}
+	}
+	return env
+}
@@ -0,0 +1,180 @@
+package goenv
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestConfig_Isolation(t *testing.T) {
+	tmpFile := createTempFile(t, ".yaml", "isolated:\n  port: 9090\n  timeout: 5s\n")
+	defer os.Remove(tmpFile)
+	os.Unsetenv("isolated.port")
+
+	c := NewConfig()
+	if err := c.Load(tmpFile); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if got := Get(c, "isolated.port", 8080); got != 9090 {
+		t.Errorf("Get() = %v, want 9090", got)
+	}
+	if got := Get(c, "isolated.timeout", time.Second); got != 5*time.Second {
+		t.Errorf("Get() = %v, want 5s", got)
+	}
+	if got := os.Getenv("isolated.port"); got != "" {
+		t.Errorf("Load() modified the process environment: isolated.port = %v", got)
+	}
+	if got := GetEnv("isolated.port", 8080); got != 8080 {
+		t.Errorf("GetEnv() = %v, want 8080", got)
+	}
+
+	other := NewConfig()
+	other.Set("isolated.port", "7070")
+	if got := Get(other, "isolated.port", 0); got != 7070 {
+		t.Errorf("Get() = %v, want 7070", got)
+	}
+	if got := Get(c, "isolated.port", 0); got != 9090 {
+		t.Errorf("Get() after setting another config = %v, want 9090", got)
+	}
+
+	c.Unset("isolated.port")
+	if _, ok := c.Lookup("isolated.port"); ok {
+		t.Error("Lookup() after Unset() reports the key as set")
+	}
+}
+
+func TestConfig_EnvFallback(t *testing.T) {
+	os.Setenv("FALLBACK_ONLY", "from-env")
+	os.Setenv("FALLBACK_BOTH", "from-env")
+	defer os.Unsetenv("FALLBACK_ONLY")
+	defer os.Unsetenv("FALLBACK_BOTH")
+
+	tests := []struct {
+		name   string
+		config *Config
+		key    string
+		want   string
+	}{
+		{"isolated ignores env", NewConfig(), "FALLBACK_ONLY", "default"},
+		{"fallback reads env", NewConfig(WithEnvFallback()), "FALLBACK_ONLY", "from-env"},
+		{"own value wins", NewConfig(WithEnvFallback()), "FALLBACK_BOTH", "from-config"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.Set("FALLBACK_BOTH", "from-config")
+			if got := Get(tt.config, tt.key, "default"); got != tt.want {
+				t.Errorf("Get() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_Default(t *testing.T) {
+	defer os.Unsetenv("DEFAULT_CONFIG_KEY")
+
+	Default().Set("DEFAULT_CONFIG_KEY", "42")
+	if got := os.Getenv("DEFAULT_CONFIG_KEY"); got != "42" {
+		t.Errorf("DEFAULT_CONFIG_KEY = %v, want 42", got)
+	}
+	if got := GetEnvInt("DEFAULT_CONFIG_KEY", 0); got != 42 {
+		t.Errorf("GetEnvInt() = %v, want 42", got)
+	}
+}
+
+func TestConfig_Concurrent(t *testing.T) {
+	c := NewConfig()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key := fmt.Sprintf("worker.%d", i)
+			for j := 0; j < 100; j++ {
+				c.Set(key, fmt.Sprint(j))
+				Get(c, key, 0)
+				c.Keys()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if got := len(c.Keys()); got != 8 {
+		t.Errorf("Keys() returned %d keys, want 8", got)
+	}
+}
+
+func TestOnConfigChange(t *testing.T) {
+	c := NewConfig()
+	c.Set("PORT", "8080")
+	c.Set("LOG_LEVEL", "info")
+
+	type change struct{ old, new int }
+	var ports []change
+	cancel := OnConfigChange(c, "PORT", func(old, new int) {
+		ports = append(ports, change{old, new})
+	})
+	var levels []string
+	OnConfigChange(c, "LOG_LEVEL", func(old, new string) {
+		levels = append(levels, old+"->"+new)
+	})
+
+	c.Set("PORT", "08080") // same parsed value
+	c.Set("OTHER", "1")
+	c.Set("PORT", "9090")
+	c.Set("LOG_LEVEL", "debug")
+	c.Unset("PORT")
+	cancel()
+	c.Set("PORT", "7070")
+
+	wantPorts := []change{{8080, 9090}, {9090, 0}}
+	if !reflect.DeepEqual(ports, wantPorts) {
+		t.Errorf("PORT changes = %v, want %v", ports, wantPorts)
+	}
+	if want := []string{"info->debug"}; !reflect.DeepEqual(levels, want) {
+		t.Errorf("LOG_LEVEL changes = %v, want %v", levels, want)
+	}
+}
+
+func TestOnChange_Default(t *testing.T) {
+	defer os.Unsetenv("ONCHANGE_TIMEOUT")
+
+	var got []time.Duration
+	cancel := OnChange("ONCHANGE_TIMEOUT", func(old, new time.Duration) {
+		got = append(got, old, new)
+	})
+	defer cancel()
+
+	Default().Set("ONCHANGE_TIMEOUT", "5s")
+	if want := []time.Duration{0, 5 * time.Second}; !reflect.DeepEqual(got, want) {
+		t.Errorf("changes = %v, want %v", got, want)
+	}
+}
+
+func TestConfig_Subscribe(t *testing.T) {
+	tmpFile := createTempFile(t, ".env", "A=1\nB=2\n")
+	defer os.Remove(tmpFile)
+
+	c := NewConfig()
+	c.Set("A", "1")
+
+	var diffs []Diff
+	c.Subscribe(func(d Diff) { diffs = append(diffs, d) })
+
+	if err := c.Load(tmpFile); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	c.Set("B", "2")
+
+	if len(diffs) != 1 {
+		t.Fatalf("got %d notifications, want 1", len(diffs))
+	}
+	if want := []string{"B"}; !reflect.DeepEqual(diffs[0].Added, want) || len(diffs[0].Changed) != 0 {
+		t.Errorf("diff = %v, want added: B", diffs[0])
+	}
+}
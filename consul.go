@@ -0,0 +1,157 @@
+package goenv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultConsulWaitTime = 5 * time.Minute
+
+// ConsulSource loads every key under a Consul KV prefix, named like the
+// keys of EtcdSource.
+type ConsulSource struct {
+	Address    string        // Consul address, defaults to $CONSUL_HTTP_ADDR or http://127.0.0.1:8500
+	Prefix     string        // Key directory to list, e.g. "config/myapp"
+	Token      string        // ACL token, defaults to $CONSUL_HTTP_TOKEN
+	Datacenter string        // Optional datacenter
+	Decode     bool          // Decode values of keys ending in .json, .yaml or .yml
+	Blocking   bool          // Wait for a change on every Load after the first one
+	WaitTime   time.Duration // Maximum wait of a blocking query, defaults to 5m
+	Timeout    time.Duration
+	Client     *http.Client
+
+	mu    sync.Mutex
+	index uint64
+}
+
+// NewConsulSource creates a ConsulSource for a key prefix, reading the
+// address and token from the standard Consul environment variables
+func NewConsulSource(prefix string) *ConsulSource {
+	address := os.Getenv("CONSUL_HTTP_ADDR")
+	if address == "" {
+		address = "http://127.0.0.1:8500"
+	} else if !isURL(address) {
+		address = "http://" + address
+	}
+
+	return &ConsulSource{
+		Address: address,
+		Prefix:  prefix,
+		Token:   os.Getenv("CONSUL_HTTP_TOKEN"),
+	}
+}
+
+// Load lists the prefix and returns its keys. With Blocking set, every call
+// after the first one waits until the prefix changes or WaitTime elapses,
+// which makes Load suitable for driving a reload loop.
+func (s *ConsulSource) Load(ctx context.Context) (map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	query := url.Values{}
+	query.Set("recurse", "true")
+	if s.Datacenter != "" {
+		query.Set("dc", s.Datacenter)
+	}
+
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = defaultHTTPTimeout
+	}
+	if s.Blocking && s.index > 0 {
+		wait := s.WaitTime
+		if wait <= 0 {
+			wait = defaultConsulWaitTime
+		}
+		query.Set("index", strconv.FormatUint(s.index, 10))
+		query.Set("wait", wait.String())
+		// Consul may add up to wait/16 of jitter to a blocking query
+		timeout += wait + wait/16
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// Consul matches keys by plain string prefix, so config/myapp would also
+	// list config/myapp2
+	prefix := dirPrefix(strings.TrimLeft(s.Prefix, "/"))
+	endpoint := fmt.Sprintf("%s/v1/kv/%s?%s", strings.TrimRight(s.Address, "/"), prefix, query.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	if s.Token != "" {
+		req.Header.Set("X-Consul-Token", s.Token)
+	}
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("consul list %s: %w", s.Prefix, err)
+	}
+	defer resp.Body.Close()
+
+	var pairs []struct {
+		Key   string
+		Value []byte
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		if err := json.NewDecoder(resp.Body).Decode(&pairs); err != nil {
+			return nil, fmt.Errorf("consul list %s: %w", s.Prefix, err)
+		}
+	case http.StatusNotFound:
+		// The prefix holds no keys yet
+	default:
+		return nil, fmt.Errorf("consul list %s: unexpected status %s", s.Prefix, resp.Status)
+	}
+
+	if index, err := strconv.ParseUint(resp.Header.Get("X-Consul-Index"), 10, 64); err == nil {
+		// An index that goes backwards must reset the blocking query
+		if index < s.index {
+			index = 0
+		}
+		s.index = index
+	}
+
+	values := make(map[string]string)
+	for _, pair := range pairs {
+		if strings.HasSuffix(pair.Key, "/") {
+			// Folder entry
+			continue
+		}
+
+		key := pathToKey(prefix, pair.Key)
+		if key == "" {
+			continue
+		}
+
+		if err := decodeValue(key, pair.Value, s.Decode, values); err != nil {
+			return nil, fmt.Errorf("consul key %s: %w", pair.Key, err)
+		}
+	}
+	return values, nil
+}
+
+// Index returns the Consul index of the last successful Load
+func (s *ConsulSource) Index() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.index
+}
+
+// String returns the location of the prefix
+func (s *ConsulSource) String() string {
+	return "consul:" + s.Prefix
+}
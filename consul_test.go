@@ -0,0 +1,138 @@
+package goenv
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// consulStub serves a minimal Consul KV list endpoint with blocking queries
+type consulStub struct {
+	mu      sync.Mutex
+	index   uint64
+	pairs   map[string]string
+	changed chan struct{}
+}
+
+func (c *consulStub) set(key, value string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pairs[key] = value
+	c.index++
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+func (c *consulStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("X-Consul-Token") != "acl-token" {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	c.mu.Lock()
+	changed := c.changed
+	index := c.index
+	c.mu.Unlock()
+
+	if wait, err := strconv.ParseUint(r.URL.Query().Get("index"), 10, 64); err == nil && wait >= index {
+		select {
+		case <-changed:
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	type pair struct {
+		Key   string
+		Value []byte
+	}
+	// Like Consul, match keys by plain string prefix
+	prefix := strings.TrimPrefix(r.URL.Path, "/v1/kv/")
+	var pairs []pair
+	for key, value := range c.pairs {
+		if strings.HasPrefix(key, prefix) {
+			pairs = append(pairs, pair{Key: key, Value: []byte(value)})
+		}
+	}
+	pairs = append(pairs, pair{Key: "config/myapp/"})
+	w.Header().Set("X-Consul-Index", strconv.FormatUint(c.index, 10))
+	json.NewEncoder(w).Encode(pairs)
+}
+
+func TestConsulSource(t *testing.T) {
+	stub := &consulStub{
+		index: 1,
+		pairs: map[string]string{
+			"config/myapp/db/host":       "localhost",
+			"config/myapp/db/port":       "5432",
+			"config/myapp/features.json": `{"auth": true, "limits": {"rps": 100}}`,
+			"config/myapp2/db/host":      "sibling",
+		},
+		changed: make(chan struct{}),
+	}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	source := &ConsulSource{Address: server.URL, Prefix: "config/myapp", Token: "acl-token", Decode: true}
+	values, err := source.Load(context.Background())
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	want := map[string]string{
+		"db.host":             "localhost",
+		"db.port":             "5432",
+		"features.auth":       "true",
+		"features.limits.rps": "100",
+	}
+	for key, value := range want {
+		if got := values[key]; got != value {
+			t.Errorf("Load()[%s] = %v, want %v", key, got, value)
+		}
+	}
+	if len(values) != len(want) {
+		t.Errorf("Load() returned %d keys, want %d: %v", len(values), len(want), values)
+	}
+	if got := source.Index(); got != 1 {
+		t.Errorf("Index() = %d, want 1", got)
+	}
+}
+
+func TestConsulSource_Blocking(t *testing.T) {
+	stub := &consulStub{
+		index:   1,
+		pairs:   map[string]string{"config/myapp/log/level": "info"},
+		changed: make(chan struct{}),
+	}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	source := &ConsulSource{Address: server.URL, Prefix: "config/myapp/", Token: "acl-token", Blocking: true}
+	if _, err := source.Load(context.Background()); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		stub.set("config/myapp/log/level", "debug")
+	}()
+
+	values, err := source.Load(context.Background())
+	if err != nil {
+		t.Fatalf("blocking Load() error = %v", err)
+	}
+	if got := values["log.level"]; got != "debug" {
+		t.Errorf("log.level = %v, want debug", got)
+	}
+	if got := source.Index(); got != 2 {
+		t.Errorf("Index() = %d, want 2", got)
+	}
+}
@@ -0,0 +1,237 @@
+package goenv
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// KeySize is the size in bytes of an encryption key (AES-256)
+const KeySize = 32
+
+// encryptedHeader starts every encrypted file. It is also authenticated as
+// additional data, so the version cannot be changed without detection.
+const encryptedHeader = "goenv:v1:aes256gcm:"
+
+// ErrDecryption is returned when encrypted data cannot be authenticated,
+// because the key is wrong or the data has been tampered with
+var ErrDecryption = errors.New("decryption failed: wrong key or tampered data")
+
+var (
+	encryptionKeyMu sync.RWMutex
+	encryptionKey   []byte
+)
+
+// SetEncryptionKey sets the key used to decrypt encrypted files and values.
+// When no key is set, it is read from $GOENV_KEY or from the file named by
+// $GOENV_KEY_FILE, both holding a base64 encoded key.
+func SetEncryptionKey(key []byte) error {
+	if key != nil && len(key) != KeySize {
+		return fmt.Errorf("encryption key must be %d bytes, got %d", KeySize, len(key))
+	}
+
+	encryptionKeyMu.Lock()
+	defer encryptionKeyMu.Unlock()
+	if key == nil {
+		encryptionKey = nil
+	} else {
+		encryptionKey = append([]byte(nil), key...)
+	}
+	return nil
+}
+
+// GenerateKey returns a new random encryption key, base64 encoded for use in
+// $GOENV_KEY or a key file
+func GenerateKey() (string, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// ParseKey decodes a base64 encoded encryption key
+func ParseKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key: %w", err)
+	}
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("encryption key must be %d bytes, got %d", KeySize, len(key))
+	}
+	return key, nil
+}
+
+// currentKey returns the configured encryption key
+func currentKey() ([]byte, error) {
+	encryptionKeyMu.RLock()
+	key := encryptionKey
+	encryptionKeyMu.RUnlock()
+	if key != nil {
+		return key, nil
+	}
+
+	if encoded := os.Getenv("GOENV_KEY"); encoded != "" {
+		return ParseKey(encoded)
+	}
+	if path := os.Getenv("GOENV_KEY_FILE"); path != "" {
+		encoded, err := readSecretFile(path, SecretFileOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("GOENV_KEY_FILE: %w", err)
+		}
+		return ParseKey(encoded)
+	}
+	return nil, errors.New("no encryption key configured, set GOENV_KEY or GOENV_KEY_FILE")
+}
+
+// Encrypt encrypts plaintext with AES-256-GCM and returns the encrypted file
+// content
+func Encrypt(plaintext, key []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	sealed := gcm.Seal(nonce, nonce, plaintext, []byte(encryptedHeader))
+
+	out := make([]byte, 0, len(encryptedHeader)+base64.StdEncoding.EncodedLen(len(sealed))+1)
+	out = append(out, encryptedHeader...)
+	out = base64.StdEncoding.AppendEncode(out, sealed)
+	return append(out, '\n'), nil
+}
+
+// Decrypt decrypts content produced by Encrypt. It returns an error wrapping
+// ErrDecryption when the content fails authentication.
+func Decrypt(data, key []byte) ([]byte, error) {
+	data = bytes.TrimSpace(data)
+	if !bytes.HasPrefix(data, []byte(encryptedHeader)) {
+		return nil, fmt.Errorf("%w: not an encrypted goenv file", ErrDecryption)
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(string(data[len(encryptedHeader):]))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDecryption, err)
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, ErrDecryption
+	}
+
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, []byte(encryptedHeader))
+	if err != nil {
+		return nil, ErrDecryption
+	}
+	return plaintext, nil
+}
+
+// EncryptFile encrypts the file src and writes the result to dst
+func EncryptFile(src, dst string, key []byte) error {
+	plaintext, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	encrypted, err := Encrypt(plaintext, key)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(dst, encrypted)
+}
+
+// DecryptFile decrypts the file src and writes the plaintext to dst
+func DecryptFile(src, dst string, key []byte) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	plaintext, err := Decrypt(data, key)
+	if err != nil {
+		return fmt.Errorf("%s: %w", src, err)
+	}
+	return writeFileAtomic(dst, plaintext)
+}
+
+// RotateKey re-encrypts the file at path from oldKey to newKey in place
+func RotateKey(path string, oldKey, newKey []byte) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	plaintext, err := Decrypt(data, oldKey)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	encrypted, err := Encrypt(plaintext, newKey)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, encrypted)
+}
+
+// parseEncrypted decrypts an encrypted key-value document with the
+// configured key and parses the plaintext
+func parseEncrypted(data []byte) (map[string]string, error) {
+	key, err := currentKey()
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := Decrypt(data, key)
+	if err != nil {
+		return nil, err
+	}
+	return parseKeyValue(bytes.NewReader(plaintext))
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("encryption key must be %d bytes, got %d", KeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames it
+// into place, so readers never observe a partially written file. New files
+// are created with mode 0600, existing files keep their mode.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if info, err := os.Stat(path); err == nil {
+		if err := tmp.Chmod(info.Mode().Perm()); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
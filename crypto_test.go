@@ -0,0 +1,149 @@
+package goenv
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func testKey(t *testing.T) []byte {
+	encoded, err := GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	key, err := ParseKey(encoded)
+	if err != nil {
+		t.Fatalf("ParseKey() error = %v", err)
+	}
+	return key
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	key := testKey(t)
+	plaintext := []byte("DB_PASSWORD=s3cr3t\n")
+
+	encrypted, err := Encrypt(plaintext, key)
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+	if bytes.Contains(encrypted, []byte("s3cr3t")) {
+		t.Error("Encrypt() output contains the plaintext")
+	}
+
+	decrypted, err := Decrypt(encrypted, key)
+	if err != nil {
+		t.Fatalf("Decrypt() error = %v", err)
+	}
+	if !bytes.Equal(decrypted, plaintext) {
+		t.Errorf("Decrypt() = %q, want %q", decrypted, plaintext)
+	}
+
+	// Replace one base64 character of the ciphertext with another
+	tampered := append([]byte(nil), encrypted...)
+	i := len(encryptedHeader) + 20
+	if tampered[i] == 'A' {
+		tampered[i] = 'B'
+	} else {
+		tampered[i] = 'A'
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		key  []byte
+	}{
+		{"wrong key", encrypted, testKey(t)},
+		{"tampered data", tampered, key},
+		{"missing header", plaintext, key},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Decrypt(tt.data, tt.key); !errors.Is(err, ErrDecryption) {
+				t.Errorf("Decrypt() error = %v, want ErrDecryption", err)
+			}
+		})
+	}
+}
+
+func TestLoadEnv_Encrypted(t *testing.T) {
+	key := testKey(t)
+	dir := t.TempDir()
+	plainFile := filepath.Join(dir, "prod.env")
+	encFile := filepath.Join(dir, "prod.env.enc")
+	fallbackFile := filepath.Join(dir, "fallback.env")
+	os.WriteFile(plainFile, []byte("ENC_DB_PASSWORD=s3cr3t\n"), 0o600)
+	os.WriteFile(fallbackFile, []byte("ENC_DB_PASSWORD=fallback\n"), 0o600)
+
+	if err := EncryptFile(plainFile, encFile, key); err != nil {
+		t.Fatalf("EncryptFile() error = %v", err)
+	}
+	defer os.Unsetenv("ENC_DB_PASSWORD")
+	defer SetEncryptionKey(nil)
+
+	t.Run("missing key", func(t *testing.T) {
+		os.Unsetenv("GOENV_KEY")
+		os.Unsetenv("GOENV_KEY_FILE")
+		if err := LoadEnv(encFile); err == nil {
+			t.Error("LoadEnv() expected error without a key")
+		}
+	})
+
+	t.Run("key from key file", func(t *testing.T) {
+		keyFile := filepath.Join(dir, "key")
+		encoded, _ := GenerateKey()
+		rotated, _ := ParseKey(encoded)
+		os.WriteFile(keyFile, []byte(encoded+"\n"), 0o600)
+		os.Setenv("GOENV_KEY_FILE", keyFile)
+		defer os.Unsetenv("GOENV_KEY_FILE")
+
+		if err := RotateKey(encFile, key, rotated); err != nil {
+			t.Fatalf("RotateKey() error = %v", err)
+		}
+		defer RotateKey(encFile, rotated, key)
+
+		if err := LoadEnv(encFile); err != nil {
+			t.Fatalf("LoadEnv() error = %v", err)
+		}
+		if got := os.Getenv("ENC_DB_PASSWORD"); got != "s3cr3t" {
+			t.Errorf("ENC_DB_PASSWORD = %v, want s3cr3t", got)
+		}
+	})
+
+	t.Run("configured key", func(t *testing.T) {
+		os.Unsetenv("ENC_DB_PASSWORD")
+		if err := SetEncryptionKey(key); err != nil {
+			t.Fatalf("SetEncryptionKey() error = %v", err)
+		}
+		if err := LoadEnv(encFile); err != nil {
+			t.Fatalf("LoadEnv() error = %v", err)
+		}
+		if got := os.Getenv("ENC_DB_PASSWORD"); got != "s3cr3t" {
+			t.Errorf("ENC_DB_PASSWORD = %v, want s3cr3t", got)
+		}
+	})
+
+	t.Run("tampered file fails without fallback", func(t *testing.T) {
+		os.Unsetenv("ENC_DB_PASSWORD")
+		SetEncryptionKey(testKey(t))
+
+		err := LoadEnv(encFile, fallbackFile)
+		if !errors.Is(err, ErrDecryption) {
+			t.Errorf("LoadEnv() error = %v, want ErrDecryption", err)
+		}
+		if got := os.Getenv("ENC_DB_PASSWORD"); got != "" {
+			t.Errorf("ENC_DB_PASSWORD = %v, want it unset", got)
+		}
+	})
+
+	t.Run("decrypt file", func(t *testing.T) {
+		out := filepath.Join(dir, "decrypted.env")
+		if err := DecryptFile(encFile, out, key); err != nil {
+			t.Fatalf("DecryptFile() error = %v", err)
+		}
+		if data, _ := os.ReadFile(out); string(data) != "ENC_DB_PASSWORD=s3cr3t\n" {
+			t.Errorf("DecryptFile() wrote %q", data)
+		}
+	})
+}
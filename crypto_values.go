@@ -0,0 +1,383 @@
+package goenv
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// encryptedValuePattern matches an inline encrypted value such as
+// ENC[AES256_GCM,data:...,iv:...,tag:...]
+var encryptedValuePattern = regexp.MustCompile(`^ENC\[AES256_GCM,data:([A-Za-z0-9+/=]*),iv:([A-Za-z0-9+/=]+),tag:([A-Za-z0-9+/=]+)\]$`)
+
+// EncryptValue encrypts a single value with AES-256-GCM into the inline
+// ENC[AES256_GCM,data:...,iv:...,tag:...] form. The name of the key is
+// authenticated too, so an encrypted value cannot be moved to another key.
+func EncryptValue(name, value string, key []byte) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+
+	iv := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(iv); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nil, iv, []byte(value), []byte(name))
+	data, tag := sealed[:len(sealed)-gcm.Overhead()], sealed[len(sealed)-gcm.Overhead():]
+
+	enc := base64.StdEncoding
+	return fmt.Sprintf("ENC[AES256_GCM,data:%s,iv:%s,tag:%s]",
+		enc.EncodeToString(data), enc.EncodeToString(iv), enc.EncodeToString(tag)), nil
+}
+
+// DecryptValue decrypts an inline encrypted value of the key name. It returns
+// an error wrapping ErrDecryption when the value fails authentication.
+func DecryptValue(name, value string, key []byte) (string, error) {
+	match := encryptedValuePattern.FindStringSubmatch(value)
+	if match == nil {
+		return "", fmt.Errorf("%w: malformed encrypted value", ErrDecryption)
+	}
+
+	var parts [3][]byte
+	for i := range parts {
+		decoded, err := base64.StdEncoding.DecodeString(match[i+1])
+		if err != nil {
+			return "", fmt.Errorf("%w: %v", ErrDecryption, err)
+		}
+		parts[i] = decoded
+	}
+	data, iv, tag := parts[0], parts[1], parts[2]
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	if len(iv) != gcm.NonceSize() || len(tag) != gcm.Overhead() {
+		return "", fmt.Errorf("%w: invalid iv or tag size", ErrDecryption)
+	}
+
+	plaintext, err := gcm.Open(nil, iv, append(data, tag...), []byte(name))
+	if err != nil {
+		return "", ErrDecryption
+	}
+	return string(plaintext), nil
+}
+
+// isEncryptedValue reports whether value is in the inline encrypted form
+func isEncryptedValue(value string) bool {
+	return strings.HasPrefix(value, "ENC[") && strings.HasSuffix(value, "]")
+}
+
+// decryptValues decrypts every inline encrypted value in values in place.
+// The key is only looked up when an encrypted value is present.
+func decryptValues(values map[string]string) error {
+	var names []string
+	for name, value := range values {
+		if isEncryptedValue(value) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	sort.Strings(names)
+
+	key, err := currentKey()
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		plaintext, err := DecryptValue(name, values[name], key)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		values[name] = plaintext
+	}
+	return nil
+}
+
+// EncryptKeys encrypts the values of the given keys in a key-value, JSON or
+// YAML file in place. Nested JSON and YAML keys use dot notation. Other keys,
+// comments and values that are already encrypted are left untouched.
+func EncryptKeys(path string, key []byte, keys ...string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	selected := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		selected[k] = true
+	}
+
+	var out []byte
+	switch detectFormat(path) {
+	case FormatKeyValue:
+		out, err = encryptKeyValueKeys(data, key, selected)
+	case FormatJSON:
+		out, err = encryptJSONKeys(data, key, selected)
+	case FormatYAML:
+		out, err = encryptNodeKeys(data, key, selected)
+	default:
+		return fmt.Errorf("cannot encrypt keys of %s: unsupported file format", path)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	if len(selected) > 0 {
+		missing := make([]string, 0, len(selected))
+		for k := range selected {
+			missing = append(missing, k)
+		}
+		sort.Strings(missing)
+		return fmt.Errorf("%s: keys not found: %s", path, strings.Join(missing, ", "))
+	}
+	return writeFileAtomic(path, out)
+}
+
+// encryptKeyValueKeys rewrites the selected lines of a key-value document,
+// keeping their inline comments. Found keys are removed from selected.
+func encryptKeyValueKeys(data, key []byte, selected map[string]bool) ([]byte, error) {
+	var out bytes.Buffer
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		name, value, ok := parseKeyValueLine(line)
+		if !ok || !selected[name] {
+			out.WriteString(line + "\n")
+			continue
+		}
+		delete(selected, name)
+
+		if !isEncryptedValue(value) {
+			encrypted, err := EncryptValue(name, value, key)
+			if err != nil {
+				return nil, err
+			}
+
+			prefix := line[:strings.Index(line, "=")+1]
+			comment := ""
+			if i := findComment(line); i != -1 {
+				comment = "  " + line[i:]
+			}
+			line = prefix + encrypted + comment
+		}
+		out.WriteString(line + "\n")
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// encryptNodeKeys encrypts the selected scalars of a YAML document. Found
+// keys are removed from selected.
+func encryptNodeKeys(data, key []byte, selected map[string]bool) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	var walk func(prefix string, node *yaml.Node) error
+	walk = func(prefix string, node *yaml.Node) error {
+		if node.Kind == yaml.DocumentNode {
+			for _, child := range node.Content {
+				if err := walk(prefix, child); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			name := node.Content[i].Value
+			if prefix != "" {
+				name = prefix + "." + name
+			}
+			value := node.Content[i+1]
+
+			if value.Kind == yaml.MappingNode {
+				if err := walk(name, value); err != nil {
+					return err
+				}
+				continue
+			}
+			if !selected[name] || value.Kind != yaml.ScalarNode {
+				continue
+			}
+			delete(selected, name)
+
+			if value.Tag == "!!null" || isEncryptedValue(value.Value) {
+				continue
+			}
+			encrypted, err := EncryptValue(name, value.Value, key)
+			if err != nil {
+				return err
+			}
+			value.Value = encrypted
+			value.Tag = "!!str"
+			value.Style = 0
+		}
+		return nil
+	}
+	if err := walk("", &doc); err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// jsonFrame is an object or array being rewritten by encryptJSONKeys
+type jsonFrame struct {
+	object     bool   // Object rather than array
+	selectable bool   // Members can be selected by their dotted key
+	prefix     string // Dotted key of the object
+	name       string // Dotted key of the member being written
+	count      int    // Number of members written so far
+	key        bool   // The next token is a member name
+}
+
+// encryptJSONKeys encrypts the selected scalars of a JSON document. The
+// document is re-encoded token by token, so key order and the spelling of
+// numbers are kept. Found keys are removed from selected.
+func encryptJSONKeys(data, key []byte, selected map[string]bool) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var buf bytes.Buffer
+	var stack []*jsonFrame
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		if delim, ok := tok.(json.Delim); ok && (delim == '}' || delim == ']') {
+			buf.WriteByte(byte(delim))
+			stack = stack[:len(stack)-1]
+			continue
+		}
+
+		var parent *jsonFrame
+		if len(stack) > 0 {
+			parent = stack[len(stack)-1]
+			switch {
+			case parent.object && !parent.key:
+				buf.WriteByte(':')
+			case parent.count > 0:
+				buf.WriteByte(',')
+			}
+		}
+
+		if parent != nil && parent.object && parent.key {
+			name := tok.(string)
+			parent.name, parent.key = name, false
+			if parent.prefix != "" {
+				parent.name = parent.prefix + "." + name
+			}
+			parent.count++
+			if err := writeJSONToken(&buf, name); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		name, selectable := "", parent == nil
+		if parent != nil {
+			if parent.object {
+				name, selectable = parent.name, parent.selectable
+				parent.key = true
+			} else {
+				parent.count++
+			}
+		}
+
+		if delim, ok := tok.(json.Delim); ok {
+			buf.WriteByte(byte(delim))
+			stack = append(stack, &jsonFrame{object: delim == '{', selectable: selectable, prefix: name, key: true})
+			continue
+		}
+
+		if selectable && selected[name] {
+			delete(selected, name)
+
+			var plaintext string
+			switch value := tok.(type) {
+			case string:
+				plaintext = value
+			case json.Number:
+				plaintext = value.String()
+			case bool:
+				plaintext = strconv.FormatBool(value)
+			}
+			if tok != nil && !isEncryptedValue(plaintext) {
+				encrypted, err := EncryptValue(name, plaintext, key)
+				if err != nil {
+					return nil, err
+				}
+				tok = encrypted
+			}
+		}
+		if err := writeJSONToken(&buf, tok); err != nil {
+			return nil, err
+		}
+	}
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, buf.Bytes(), "", detectJSONIndent(data)); err != nil {
+		return nil, err
+	}
+	out.WriteByte('\n')
+	return out.Bytes(), nil
+}
+
+// writeJSONToken writes a scalar token without escaping HTML characters
+func writeJSONToken(buf *bytes.Buffer, tok json.Token) error {
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(tok); err != nil {
+		return err
+	}
+	buf.Truncate(buf.Len() - 1)
+	return nil
+}
+
+// detectJSONIndent returns the indentation used by a JSON document, or two
+// spaces when it cannot be detected
+func detectJSONIndent(data []byte) string {
+	lines := strings.Split(string(data), "\n")
+	for _, line := range lines[1:] {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed != "" && len(trimmed) < len(line) {
+			return line[:len(line)-len(trimmed)]
+		}
+	}
+	return "  "
+}
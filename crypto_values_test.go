@@ -0,0 +1,155 @@
+package goenv
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEncryptDecryptValue(t *testing.T) {
+	key := testKey(t)
+
+	encrypted, err := EncryptValue("DB_PASSWORD", "s3cr3t", key)
+	if err != nil {
+		t.Fatalf("EncryptValue() error = %v", err)
+	}
+	if !strings.HasPrefix(encrypted, "ENC[AES256_GCM,data:") {
+		t.Errorf("EncryptValue() = %v, want ENC[AES256_GCM,...] form", encrypted)
+	}
+
+	got, err := DecryptValue("DB_PASSWORD", encrypted, key)
+	if err != nil {
+		t.Fatalf("DecryptValue() error = %v", err)
+	}
+	if got != "s3cr3t" {
+		t.Errorf("DecryptValue() = %v, want s3cr3t", got)
+	}
+
+	tests := []struct {
+		name  string
+		key   string
+		value string
+	}{
+		{"moved to another key", "OTHER_KEY", encrypted},
+		{"malformed", "DB_PASSWORD", "ENC[AES256_GCM,data:abc]"},
+		{"modified tag", "DB_PASSWORD", encrypted[:len(encrypted)-4] + "AAA=]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecryptValue(tt.key, tt.value, key); !errors.Is(err, ErrDecryption) {
+				t.Errorf("DecryptValue() error = %v, want ErrDecryption", err)
+			}
+		})
+	}
+}
+
+func TestEncryptKeys(t *testing.T) {
+	key := testKey(t)
+	if err := SetEncryptionKey(key); err != nil {
+		t.Fatalf("SetEncryptionKey() error = %v", err)
+	}
+	defer SetEncryptionKey(nil)
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		keys    []string
+		plain   []string // lines expected to stay readable
+		want    map[string]string
+	}{
+		{
+			name: "key-value",
+			file: "app.env",
+			content: `# Database settings
+INLINE_DB_HOST=localhost
+INLINE_DB_PASSWORD="s3cr3t"  # rotate quarterly
+`,
+			keys:  []string{"INLINE_DB_PASSWORD"},
+			plain: []string{"# Database settings", "INLINE_DB_HOST=localhost", "# rotate quarterly"},
+			want:  map[string]string{"INLINE_DB_HOST": "localhost", "INLINE_DB_PASSWORD": "s3cr3t"},
+		},
+		{
+			name: "json",
+			file: "app.json",
+			content: `{
+    "inline": {
+        "host": "localhost",
+        "password": "s3cr3t",
+        "port": 5432
+    }
+}
+`,
+			keys:  []string{"inline.password", "inline.port"},
+			plain: []string{`    "inline": {`, `        "host": "localhost",`},
+			want:  map[string]string{"inline.host": "localhost", "inline.password": "s3cr3t", "inline.port": "5432"},
+		},
+		{
+			name:    "json escapes",
+			file:    "escaped.json",
+			content: `{"inline_url": "http:\/\/x?a=1&b=2", "inline_pw": "\u0073\u0033cr3t", "inline_list": [{"inline_pw": "kept"}]}`,
+			keys:    []string{"inline_pw"},
+			plain:   []string{`"inline_url": "http://x?a=1&b=2"`, `"inline_pw": "kept"`},
+			want:    map[string]string{"inline_url": "http://x?a=1&b=2", "inline_pw": "s3cr3t"},
+		},
+		{
+			name: "yaml",
+			file: "app.yaml",
+			content: `# Database settings
+inline:
+  host: localhost
+  password: s3cr3t
+`,
+			keys:  []string{"inline.password"},
+			plain: []string{"# Database settings", "  host: localhost"},
+			want:  map[string]string{"inline.host": "localhost", "inline.password": "s3cr3t"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			os.WriteFile(path, []byte(tt.content), 0o644)
+
+			if err := EncryptKeys(path, key, tt.keys...); err != nil {
+				t.Fatalf("EncryptKeys() error = %v", err)
+			}
+			// Encrypting again leaves already encrypted values alone
+			if err := EncryptKeys(path, key, tt.keys...); err != nil {
+				t.Fatalf("EncryptKeys() second run error = %v", err)
+			}
+
+			data, _ := os.ReadFile(path)
+			if strings.Contains(string(data), "s3cr3t") {
+				t.Errorf("EncryptKeys() left the secret in plaintext:\n%s", data)
+			}
+			for _, line := range tt.plain {
+				if !strings.Contains(string(data), line) {
+					t.Errorf("EncryptKeys() output is missing %q:\n%s", line, data)
+				}
+			}
+
+			for k := range tt.want {
+				defer os.Unsetenv(k)
+			}
+			if err := LoadEnv(path); err != nil {
+				t.Fatalf("LoadEnv() error = %v", err)
+			}
+			for k, want := range tt.want {
+				if got := os.Getenv(k); got != want {
+					t.Errorf("Environment variable %s = %v, want %v", k, got, want)
+				}
+			}
+		})
+	}
+
+	t.Run("missing key", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "app.env")
+		os.WriteFile(path, []byte("A=1\n"), 0o644)
+		if err := EncryptKeys(path, key, "B"); err == nil {
+			t.Error("EncryptKeys() expected error for missing key")
+		}
+	})
+}
@@ -0,0 +1,112 @@
+package goenv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DirSource loads a directory where each file name is a key and the file
+// content is its value, such as a Kubernetes ConfigMap or Secret volume.
+//
+// Kubernetes publishes volume updates by atomically swapping the ..data
+// symlink to a new timestamped directory. DirSource resolves that symlink
+// once per Load and reads every file from the same directory, so a Load never
+// mixes files from two versions.
+type DirSource struct {
+	Dir         string
+	Decode      bool  // Decode files ending in .json, .yaml or .yml by their extension
+	MaxFileSize int64 // Reject files larger than this many bytes, 0 means no limit
+	TrimNewline bool  // Remove a single trailing newline from every value
+}
+
+// NewDirSource creates a DirSource for a directory
+func NewDirSource(dir string) *DirSource {
+	return &DirSource{Dir: dir}
+}
+
+// maxDirAttempts bounds how often Load restarts when a volume update removes
+// the directory it is reading
+const maxDirAttempts = 3
+
+// Load reads every regular file in the directory, skipping hidden ".." entries
+func (s *DirSource) Load(ctx context.Context) (map[string]string, error) {
+	var err error
+	for attempt := 0; attempt < maxDirAttempts; attempt++ {
+		var values map[string]string
+		values, err = s.load()
+		if err == nil || !errors.Is(err, os.ErrNotExist) {
+			return values, err
+		}
+		// The snapshot was removed by a concurrent update, so resolve the
+		// ..data symlink again unless the directory itself is missing
+		if _, statErr := os.Stat(s.Dir); statErr != nil {
+			return nil, err
+		}
+	}
+	return nil, err
+}
+
+// load reads a single snapshot of the directory
+func (s *DirSource) load() (map[string]string, error) {
+	dir, err := s.snapshotDir()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string)
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, "..") {
+			continue
+		}
+
+		path := filepath.Join(dir, name)
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.Mode().IsRegular() {
+			continue
+		}
+
+		if s.MaxFileSize > 0 && info.Size() > s.MaxFileSize {
+			return nil, fmt.Errorf("%s exceeds the size limit of %d bytes", path, s.MaxFileSize)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if s.TrimNewline {
+			data = []byte(trimNewline(string(data)))
+		}
+		if err := decodeValue(name, data, s.Decode, values); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return values, nil
+}
+
+// snapshotDir returns the directory holding the current version of the
+// files: the target of the ..data symlink when present, or Dir itself
+func (s *DirSource) snapshotDir() (string, error) {
+	data := filepath.Join(s.Dir, "..data")
+	if info, err := os.Lstat(data); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		return filepath.EvalSymlinks(data)
+	}
+	return s.Dir, nil
+}
+
+// String returns the directory path
+func (s *DirSource) String() string {
+	return s.Dir
+}
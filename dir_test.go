@@ -0,0 +1,112 @@
+package goenv
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeConfigMapVersion writes files into a new timestamped directory and
+// swaps the ..data symlink to it, the way the kubelet updates a volume
+func writeConfigMapVersion(t *testing.T, dir, version string, files map[string]string) {
+	versionDir := filepath.Join(dir, version)
+	if err := os.Mkdir(versionDir, 0o755); err != nil {
+		t.Fatalf("Failed to create version dir: %v", err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(versionDir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		link := filepath.Join(dir, name)
+		if _, err := os.Lstat(link); os.IsNotExist(err) {
+			if err := os.Symlink(filepath.Join("..data", name), link); err != nil {
+				t.Fatalf("Failed to create symlink: %v", err)
+			}
+		}
+	}
+
+	tmpLink := filepath.Join(dir, "..data_tmp")
+	if err := os.Symlink(version, tmpLink); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+	if err := os.Rename(tmpLink, filepath.Join(dir, "..data")); err != nil {
+		t.Fatalf("Failed to swap ..data: %v", err)
+	}
+}
+
+func TestDirSource_ConfigMap(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigMapVersion(t, dir, "..2025_01_01_00_00_00.1", map[string]string{
+		"LOG_LEVEL":   "info",
+		"server.yaml": "port: 8080\n",
+	})
+
+	source := &DirSource{Dir: dir, Decode: true}
+	values, err := source.Load(context.Background())
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	want := map[string]string{"LOG_LEVEL": "info", "server.port": "8080"}
+	for key, value := range want {
+		if got := values[key]; got != value {
+			t.Errorf("Load()[%s] = %v, want %v", key, got, value)
+		}
+	}
+	if len(values) != len(want) {
+		t.Errorf("Load() returned %d keys, want %d: %v", len(values), len(want), values)
+	}
+
+	writeConfigMapVersion(t, dir, "..2025_01_01_00_05_00.2", map[string]string{
+		"LOG_LEVEL":   "debug",
+		"server.yaml": "port: 9090\n",
+	})
+
+	values, err = source.Load(context.Background())
+	if err != nil {
+		t.Fatalf("Load() after update error = %v", err)
+	}
+	if values["LOG_LEVEL"] != "debug" || values["server.port"] != "9090" {
+		t.Errorf("Load() after update = %v, want the new version", values)
+	}
+}
+
+func TestDirSource_PlainDirectory(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "DB_PASSWORD"), []byte("s3cr3t"), 0o600)
+	os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"a": 1}`), 0o600)
+	os.Mkdir(filepath.Join(dir, "nested"), 0o755)
+
+	values, err := NewDirSource(dir).Load(context.Background())
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	want := map[string]string{"DB_PASSWORD": "s3cr3t", "config.json": `{"a": 1}`}
+	for key, value := range want {
+		if got := values[key]; got != value {
+			t.Errorf("Load()[%s] = %v, want %v", key, got, value)
+		}
+	}
+	if len(values) != len(want) {
+		t.Errorf("Load() returned %d keys, want %d: %v", len(values), len(want), values)
+	}
+
+	if _, err := NewDirSource(filepath.Join(dir, "missing")).Load(context.Background()); err == nil {
+		t.Error("Load() expected error for missing directory")
+	}
+}
+
+func TestLoadEnv_Directory(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigMapVersion(t, dir, "..2025_01_01_00_00_00.1", map[string]string{"DIR_LOADED": "yes"})
+	defer os.Unsetenv("DIR_LOADED")
+
+	if err := LoadEnv(dir); err != nil {
+		t.Fatalf("LoadEnv() error = %v", err)
+	}
+	if got := os.Getenv("DIR_LOADED"); got != "yes" {
+		t.Errorf("DIR_LOADED = %v, want yes", got)
+	}
+}
@@ -2,11 +2,13 @@ package goenv
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
-	"strconv"
+	"reflect"
 	"strings"
 	"time"
 
@@ -17,47 +19,45 @@ import (
 type FileFormat int
 
 const (
-	FormatAuto     FileFormat = iota // Auto-detect based on file extension
-	FormatKeyValue                   // .env format
-	FormatJSON                       // .json format
-	FormatYAML                       // .yaml/.yml format
+	FormatAuto      FileFormat = iota // Auto-detect based on file extension
+	FormatKeyValue                    // .env format
+	FormatJSON                        // .json format
+	FormatYAML                        // .yaml/.yml format
+	FormatEncrypted                   // .enc format, an encrypted key-value file
 )
 
-// LoadEnv loads environment variables from files with support for multiple formats
+// LoadEnv loads environment variables from files with support for multiple formats.
+// Entries starting with http:// or https:// are fetched with an HTTPSource and
+// directories are read with a DirSource.
 func LoadEnv(file ...string) error {
-	return LoadEnvWithFormat(FormatAuto, file...)
+	return defaultConfig.Load(file...)
 }
 
 // LoadEnvWithFormat loads environment variables from files with specified format
 func LoadEnvWithFormat(format FileFormat, file ...string) error {
+	return defaultConfig.LoadWithFormat(format, file...)
+}
+
+// fileSources returns a source for every non-empty file name
+func fileSources(format FileFormat, file []string) []Source {
+	sources := make([]Source, 0, len(file))
 	for _, f := range file {
 		if f == "" {
 			continue
 		}
-
-		// Determine format if auto-detect
-		detectedFormat := format
-		if format == FormatAuto {
-			detectedFormat = detectFormat(f)
-		}
-
-		var err error
-		switch detectedFormat {
-		case FormatKeyValue:
-			err = loadKeyValueFile(f)
-		case FormatJSON:
-			err = loadJSONFile(f)
-		case FormatYAML:
-			err = loadYAMLFile(f)
-		default:
-			err = fmt.Errorf("unsupported file format for %s", f)
+		if isURL(f) {
+			source := NewHTTPSource(f)
+			source.Format = format
+			sources = append(sources, source)
+			continue
 		}
-
-		if err == nil {
-			return nil
+		if info, err := os.Stat(f); err == nil && info.IsDir() {
+			sources = append(sources, &DirSource{Dir: f, Decode: format == FormatAuto})
+			continue
 		}
+		sources = append(sources, &FileSource{Path: f, Format: format})
 	}
-	return fmt.Errorf("failed to load any of the specified files")
+	return sources
 }
 
 // detectFormat detects file format based on extension
@@ -70,116 +70,181 @@ func detectFormat(filename string) FileFormat {
 		return FormatJSON
 	case ".yaml", ".yml":
 		return FormatYAML
+	case ".enc":
+		return FormatEncrypted
 	default:
 		// Default to key-value format for unknown extensions
 		return FormatKeyValue
 	}
 }
 
-// loadKeyValueFile loads environment variables from key-value format (.env)
-func loadKeyValueFile(filename string) error {
-	file, err := os.Open(filename)
+// loadFile parses a file in the given format and sets its values as
+// environment variables of the Default config
+func loadFile(filename string, format FileFormat) error {
+	data, err := os.ReadFile(filename)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
+	values, err := parseLocal(format, data, defaultConfig.Lookup)
+	if err != nil {
+		return err
+	}
 
-		// Skip empty lines and full-line comments
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
+	defaultConfig.apply(values, filename)
+	return nil
+}
+
+// parseFormat parses data in the given format into a flat map of keys to
+// values and decrypts inline ENC[...] values. Scheme values such as file: are
+// left to the caller, which resolves them with resolveValues for local
+// documents only.
+func parseFormat(format FileFormat, data []byte) (map[string]string, error) {
+	var values map[string]string
+	var err error
+	switch format {
+	case FormatAuto, FormatKeyValue:
+		values, err = parseKeyValue(bytes.NewReader(data))
+	case FormatJSON:
+		values, err = parseJSON(data)
+	case FormatYAML:
+		values, err = parseYAML(data)
+	case FormatEncrypted:
+		values, err = parseEncrypted(data)
+	default:
+		return nil, fmt.Errorf("unsupported file format %d", format)
+	}
+	if err != nil {
+		return nil, err
+	}
 
-		// Find the first # that's not inside quotes
-		commentIndex := -1
-		inQuotes := false
-		quoteChar := byte(0)
+	if err := decryptValues(values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
 
-		for i := 0; i < len(line); i++ {
-			char := line[i]
-			if !inQuotes && char == '#' {
-				commentIndex = i
-				break
-			}
-			if !inQuotes && (char == '"' || char == '\'') {
-				inQuotes = true
-				quoteChar = char
-			} else if inQuotes && char == quoteChar {
-				inQuotes = false
-				quoteChar = 0
-			}
-		}
+// loadKeyValueFile loads environment variables from key-value format (.env)
+func loadKeyValueFile(filename string) error {
+	return loadFile(filename, FormatKeyValue)
+}
 
-		// Remove comment if found
-		if commentIndex != -1 {
-			line = strings.TrimSpace(line[:commentIndex])
-		}
+// parseKeyValue parses key-value pairs (.env format) from r
+func parseKeyValue(r io.Reader) (map[string]string, error) {
+	values := make(map[string]string)
 
-		// Skip if line becomes empty after removing comment
-		if line == "" {
-			continue
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		key, value, ok := parseKeyValueLine(scanner.Text())
+		if ok {
+			values[key] = value
 		}
+	}
 
-		// Parse key=value pairs
-		parts := strings.SplitN(line, "=", 2)
-		if len(parts) != 2 {
-			continue
-		}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
 
-		key := strings.TrimSpace(parts[0])
-		value := strings.TrimSpace(parts[1])
+// parseKeyValueLine parses a single key=value line. It reports false for
+// empty lines, comments and lines without a key-value pair.
+func parseKeyValueLine(line string) (string, string, bool) {
+	line = strings.TrimSpace(line)
 
-		// Remove quotes if present
-		if len(value) >= 2 && ((value[0] == '"' && value[len(value)-1] == '"') ||
-			(value[0] == '\'' && value[len(value)-1] == '\'')) {
-			value = value[1 : len(value)-1]
-		}
+	// Skip empty lines and full-line comments
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", "", false
+	}
 
-		os.Setenv(key, value)
+	// Remove comment if found
+	if commentIndex := findComment(line); commentIndex != -1 {
+		line = strings.TrimSpace(line[:commentIndex])
 	}
 
-	return scanner.Err()
+	// Skip if line becomes empty after removing comment
+	if line == "" {
+		return "", "", false
+	}
+
+	// Parse key=value pairs
+	parts := strings.SplitN(line, "=", 2)
+	if len(parts) != 2 {
+		return "", "", false
+	}
+
+	key := strings.TrimSpace(parts[0])
+	value := strings.TrimSpace(parts[1])
+
+	// Remove quotes if present
+	if len(value) >= 2 && ((value[0] == '"' && value[len(value)-1] == '"') ||
+		(value[0] == '\'' && value[len(value)-1] == '\'')) {
+		value = value[1 : len(value)-1]
+	}
+
+	return key, value, true
+}
+
+// findComment returns the index of the first # that's not inside quotes, or
+// -1 if the line has no comment
+func findComment(line string) int {
+	inQuotes := false
+	quoteChar := byte(0)
+
+	for i := 0; i < len(line); i++ {
+		char := line[i]
+		if !inQuotes && char == '#' {
+			return i
+		}
+		if !inQuotes && (char == '"' || char == '\'') {
+			inQuotes = true
+			quoteChar = char
+		} else if inQuotes && char == quoteChar {
+			inQuotes = false
+			quoteChar = 0
+		}
+	}
+	return -1
 }
 
 // loadJSONFile loads environment variables from JSON format
 func loadJSONFile(filename string) error {
-	data, err := os.ReadFile(filename)
-	if err != nil {
-		return err
-	}
+	return loadFile(filename, FormatJSON)
+}
 
+// parseJSON parses a JSON document into a flat map using dot notation
+func parseJSON(data []byte) (map[string]string, error) {
 	var jsonData map[string]interface{}
 	if err := json.Unmarshal(data, &jsonData); err != nil {
-		return err
+		return nil, err
 	}
 
-	// Flatten nested JSON and set environment variables
-	flattenAndSetEnv("", jsonData)
-	return nil
+	// Flatten nested JSON into dotted keys
+	values := make(map[string]string)
+	flatten("", jsonData, values)
+	return values, nil
 }
 
 // loadYAMLFile loads environment variables from YAML format
 func loadYAMLFile(filename string) error {
-	data, err := os.ReadFile(filename)
-	if err != nil {
-		return err
-	}
+	return loadFile(filename, FormatYAML)
+}
 
+// parseYAML parses a YAML document into a flat map using dot notation
+func parseYAML(data []byte) (map[string]string, error) {
 	var yamlData map[string]interface{}
 	if err := yaml.Unmarshal(data, &yamlData); err != nil {
-		return err
+		return nil, err
 	}
 
-	// Flatten nested YAML and set environment variables
-	flattenAndSetEnv("", yamlData)
-	return nil
+	// Flatten nested YAML into dotted keys
+	values := make(map[string]string)
+	flatten("", yamlData, values)
+	return values, nil
 }
 
-// flattenAndSetEnv recursively flattens nested maps and sets environment variables
-func flattenAndSetEnv(prefix string, data map[string]interface{}) {
+// flatten recursively flattens nested maps into out using dot notation
+func flatten(prefix string, data map[string]interface{}, out map[string]string) {
 	for key, value := range data {
 		envKey := key
 		if prefix != "" {
@@ -189,68 +254,55 @@ func flattenAndSetEnv(prefix string, data map[string]interface{}) {
 		switch v := value.(type) {
 		case map[string]interface{}:
 			// Recursively handle nested objects
-			flattenAndSetEnv(envKey, v)
+			flatten(envKey, v, out)
 		case []interface{}:
 			// Handle arrays by converting to JSON string
 			if jsonBytes, err := json.Marshal(v); err == nil {
-				os.Setenv(envKey, string(jsonBytes))
+				out[envKey] = string(jsonBytes)
 			}
 		default:
 			// Convert other types to string
-			os.Setenv(envKey, fmt.Sprintf("%v", v))
+			out[envKey] = fmt.Sprintf("%v", v)
 		}
 	}
 }
 
 // GetEnv retrieves environment variable with type conversion and nested key support
 func GetEnv[T any](key string, defaultVal T) T {
-	val := os.Getenv(key)
-	if val == "" {
-		return defaultVal
-	}
+	return Get(defaultConfig, key, defaultVal)
+}
 
-	switch any(defaultVal).(type) {
-	case int:
-		parsed, err := strconv.Atoi(val)
-		if err != nil {
-			return defaultVal
-		}
-		return any(parsed).(T)
-	case uint64:
-		parsed, err := strconv.ParseUint(val, 10, 64)
-		if err != nil {
-			return defaultVal
-		}
-		return any(parsed).(T)
-	case int64:
-		parsed, err := strconv.ParseInt(val, 10, 64)
-		if err != nil {
-			return defaultVal
-		}
-		return any(parsed).(T)
-	case bool:
-		parsed, err := strconv.ParseBool(val)
-		if err != nil {
-			return defaultVal
-		}
-		return any(parsed).(T)
-	case float64:
-		parsed, err := strconv.ParseFloat(val, 64)
-		if err != nil {
-			return defaultVal
-		}
-		return any(parsed).(T)
-	case time.Duration:
-		parsed, err := time.ParseDuration(val)
-		if err != nil {
-			return defaultVal
-		}
-		return any(parsed).(T)
-	case string:
-		return any(val).(T)
-	default:
+// LookupEnv retrieves an environment variable with type conversion. It reports
+// whether the key is set, and returns a *ParseError naming the key, the raw
+// value and the type when the value cannot be converted. An empty value is
+// treated as unset, like GetEnv.
+func LookupEnv[T any](key string) (T, bool, error) {
+	return Lookup[T](defaultConfig, key)
+}
+
+// GetEnvE retrieves an environment variable with type conversion like GetEnv,
+// but returns a *ParseError instead of silently using defaultVal when the
+// value cannot be converted
+func GetEnvE[T any](key string, defaultVal T) (T, error) {
+	return GetE(defaultConfig, key, defaultVal)
+}
+
+// GetEnvJSON decodes an environment variable holding JSON into T, such as an
+// array of objects loaded from a YAML list or a JSON literal set by an
+// operator. It returns defaultVal when the key is unset, and a *ParseError
+// naming the key when the value is not valid JSON for T.
+func GetEnvJSON[T any](key string, defaultVal T) (T, error) {
+	return GetJSON(defaultConfig, key, defaultVal)
+}
+
+// convert converts val to the type of defaultVal, returning defaultVal when
+// the type is unsupported or val cannot be parsed
+func convert[T any](val string, defaultVal T) T {
+	parsed, err := parseValue(val, reflect.TypeOf(&defaultVal).Elem())
+	if err != nil {
 		return defaultVal
 	}
+	return parsed.Interface().(T)
 }
 
 // GetEnvNested retrieves nested environment variable using dot notation
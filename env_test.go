@@ -1,7 +1,10 @@
 package goenv
 
 import (
+	"errors"
 	"os"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 )
@@ -917,6 +920,248 @@ func TestGetEnv_Duration(t *testing.T) {
 	}
 }
 
+func TestLookupEnv(t *testing.T) {
+	os.Setenv("LOOKUP_PORT", "8080")
+	os.Setenv("LOOKUP_BAD_PORT", "80a")
+	os.Setenv("LOOKUP_EMPTY", "")
+	defer os.Unsetenv("LOOKUP_PORT")
+	defer os.Unsetenv("LOOKUP_BAD_PORT")
+	defer os.Unsetenv("LOOKUP_EMPTY")
+
+	tests := []struct {
+		name    string
+		key     string
+		want    int
+		wantOK  bool
+		wantErr bool
+	}{
+		{"valid", "LOOKUP_PORT", 8080, true, false},
+		{"malformed", "LOOKUP_BAD_PORT", 0, true, true},
+		{"empty", "LOOKUP_EMPTY", 0, false, false},
+		{"missing", "LOOKUP_MISSING", 0, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok, err := LookupEnv[int](tt.key)
+			if got != tt.want || ok != tt.wantOK || (err != nil) != tt.wantErr {
+				t.Errorf("LookupEnv() = %v, %v, %v, want %v, %v, error %v", got, ok, err, tt.want, tt.wantOK, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetEnvE(t *testing.T) {
+	os.Setenv("GETENVE_PORT", "80a")
+	os.Setenv("GETENVE_TIMEOUT", "5s")
+	defer os.Unsetenv("GETENVE_PORT")
+	defer os.Unsetenv("GETENVE_TIMEOUT")
+
+	port, err := GetEnvE("GETENVE_PORT", 8080)
+	if port != 8080 {
+		t.Errorf("GetEnvE() = %v, want the default 8080", port)
+	}
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("GetEnvE() error = %v, want a *ParseError", err)
+	}
+	if parseErr.Key != "GETENVE_PORT" || parseErr.Value != "80a" || parseErr.Type.String() != "int" {
+		t.Errorf("ParseError = %+v", parseErr)
+	}
+	if !errors.Is(err, strconv.ErrSyntax) {
+		t.Errorf("errors.Is(err, strconv.ErrSyntax) = false for %v", err)
+	}
+	if want := `GETENVE_PORT: invalid value "80a" for int: invalid syntax`; err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err, want)
+	}
+
+	if got, err := GetEnvE("GETENVE_TIMEOUT", time.Second); err != nil || got != 5*time.Second {
+		t.Errorf("GetEnvE() = %v, %v, want 5s", got, err)
+	}
+	if got, err := GetEnvE("GETENVE_MISSING", "fallback"); err != nil || got != "fallback" {
+		t.Errorf("GetEnvE() = %v, %v, want fallback", got, err)
+	}
+	if got, err := GetEnvE("GETENVE_TIMEOUT", []byte(nil)); err != nil || string(got) != "5s" {
+		t.Errorf("GetEnvE() = %q, %v, want the raw bytes 5s", got, err)
+	}
+	if _, err := GetEnvE("GETENVE_TIMEOUT", struct{}{}); err == nil {
+		t.Error("GetEnvE() with an unsupported type expected an error")
+	}
+	if got := GetEnv("GETENVE_PORT", 8080); got != 8080 {
+		t.Errorf("GetEnv() = %v, want the forgiving default 8080", got)
+	}
+}
+
+func TestGetEnv_NumericKinds(t *testing.T) {
+	defer os.Unsetenv("NUMERIC_VALUE")
+
+	tests := []struct {
+		name    string
+		value   string
+		parse   func() (any, error)
+		want    any
+		wantErr bool
+	}{
+		{"int8", "127", func() (any, error) { return GetEnvE("NUMERIC_VALUE", int8(0)) }, int8(127), false},
+		{"int8 overflow", "128", func() (any, error) { return GetEnvE("NUMERIC_VALUE", int8(0)) }, nil, true},
+		{"int16 negative", "-32768", func() (any, error) { return GetEnvE("NUMERIC_VALUE", int16(0)) }, int16(-32768), false},
+		{"int32 hex", "0x7fff_ffff", func() (any, error) { return GetEnvE("NUMERIC_VALUE", int32(0)) }, int32(0x7fffffff), false},
+		{"int32 overflow", "2147483648", func() (any, error) { return GetEnvE("NUMERIC_VALUE", int32(0)) }, nil, true},
+		{"int underscores", "1_000_000", func() (any, error) { return GetEnvE("NUMERIC_VALUE", 0) }, 1000000, false},
+		{"int leading zero is decimal", "0755", func() (any, error) { return GetEnvE("NUMERIC_VALUE", 0) }, 755, false},
+		{"int octal prefix", "0o755", func() (any, error) { return GetEnvE("NUMERIC_VALUE", 0) }, 0o755, false},
+		{"int binary prefix", "-0b101", func() (any, error) { return GetEnvE("NUMERIC_VALUE", 0) }, -5, false},
+		{"int leading zero with underscore", "0_755", func() (any, error) { return GetEnvE("NUMERIC_VALUE", 0) }, 755, false},
+		{"int64 zero", "000", func() (any, error) { return GetEnvE("NUMERIC_VALUE", int64(1)) }, int64(0), false},
+		{"int misplaced underscore", "1__0", func() (any, error) { return GetEnvE("NUMERIC_VALUE", 0) }, nil, true},
+		{"uint8", "255", func() (any, error) { return GetEnvE("NUMERIC_VALUE", uint8(0)) }, uint8(255), false},
+		{"uint8 overflow", "256", func() (any, error) { return GetEnvE("NUMERIC_VALUE", uint8(0)) }, nil, true},
+		{"uint16 port", "8080", func() (any, error) { return GetEnvE("NUMERIC_VALUE", uint16(0)) }, uint16(8080), false},
+		{"uint16 overflow", "65536", func() (any, error) { return GetEnvE("NUMERIC_VALUE", uint16(0)) }, nil, true},
+		{"uint32 hex", "0x1F", func() (any, error) { return GetEnvE("NUMERIC_VALUE", uint32(0)) }, uint32(31), false},
+		{"uint negative", "-1", func() (any, error) { return GetEnvE("NUMERIC_VALUE", uint(0)) }, nil, true},
+		{"uintptr", "0xff", func() (any, error) { return GetEnvE("NUMERIC_VALUE", uintptr(0)) }, uintptr(255), false},
+		{"float32", "1.5", func() (any, error) { return GetEnvE("NUMERIC_VALUE", float32(0)) }, float32(1.5), false},
+		{"float32 overflow", "1e40", func() (any, error) { return GetEnvE("NUMERIC_VALUE", float32(0)) }, nil, true},
+		{"float64 underscores", "1_000.25", func() (any, error) { return GetEnvE("NUMERIC_VALUE", 0.0) }, 1000.25, false},
+		{"float64 hex", "0x1p-2", func() (any, error) { return GetEnvE("NUMERIC_VALUE", 0.0) }, 0.25, false},
+		{"float64 hex integer", "0x1F", func() (any, error) { return GetEnvE("NUMERIC_VALUE", 0.0) }, 31.0, false},
+		{"float64 octal integer", "0o17", func() (any, error) { return GetEnvE("NUMERIC_VALUE", 0.0) }, 15.0, false},
+		{"float32 binary integer", "-0b101", func() (any, error) { return GetEnvE("NUMERIC_VALUE", float32(0)) }, float32(-5), false},
+		{"float64 hex fraction without exponent", "0x1.8", func() (any, error) { return GetEnvE("NUMERIC_VALUE", 0.0) }, nil, true},
+		{"complex64", "1.5+2i", func() (any, error) { return GetEnvE("NUMERIC_VALUE", complex64(0)) }, complex64(1.5 + 2i), false},
+		{"complex64 overflow", "1e40+1i", func() (any, error) { return GetEnvE("NUMERIC_VALUE", complex64(0)) }, nil, true},
+		{"complex128 parenthesized", "(-3-0.5i)", func() (any, error) { return GetEnvE("NUMERIC_VALUE", complex128(0)) }, complex(-3, -0.5), false},
+		{"complex128 real only", "4", func() (any, error) { return GetEnvE("NUMERIC_VALUE", 0i) }, complex(4, 0), false},
+		{"complex128 invalid", "1+", func() (any, error) { return GetEnvE("NUMERIC_VALUE", 0i) }, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Setenv("NUMERIC_VALUE", tt.value)
+			got, err := tt.parse()
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("GetEnvE() = %v, expected an error", got)
+				}
+			} else if err != nil || got != tt.want {
+				t.Errorf("GetEnvE() = %v (%T), %v, want %v (%T)", got, got, err, tt.want, tt.want)
+			}
+		})
+	}
+
+	os.Setenv("NUMERIC_VALUE", "70000")
+	if got := GetEnv("NUMERIC_VALUE", uint16(8080)); got != 8080 {
+		t.Errorf("GetEnv() on overflow = %v, want the default 8080", got)
+	}
+}
+
+func TestGetEnv_DefinedTypes(t *testing.T) {
+	type Port uint16
+	type Mode string
+	type Enabled bool
+	type Ratio float32
+	type Level int8
+	type Timeout time.Duration
+
+	os.Setenv("DEFINED_PORT", "8443")
+	os.Setenv("DEFINED_MODE", "release")
+	os.Setenv("DEFINED_ENABLED", "true")
+	os.Setenv("DEFINED_RATIO", "0.25")
+	os.Setenv("DEFINED_LEVEL", "-1")
+	os.Setenv("DEFINED_TIMEOUT", "5s")
+	defer func() {
+		for _, key := range []string{"DEFINED_PORT", "DEFINED_MODE", "DEFINED_ENABLED", "DEFINED_RATIO", "DEFINED_LEVEL", "DEFINED_TIMEOUT"} {
+			os.Unsetenv(key)
+		}
+	}()
+
+	if got := GetEnv("DEFINED_PORT", Port(80)); got != 8443 {
+		t.Errorf("GetEnv(Port) = %v, want 8443", got)
+	}
+	if got := GetEnv("DEFINED_MODE", Mode("debug")); got != "release" {
+		t.Errorf("GetEnv(Mode) = %v, want release", got)
+	}
+	if got := GetEnv("DEFINED_ENABLED", Enabled(false)); !bool(got) {
+		t.Errorf("GetEnv(Enabled) = %v, want true", got)
+	}
+	if got := GetEnv("DEFINED_RATIO", Ratio(1)); got != 0.25 {
+		t.Errorf("GetEnv(Ratio) = %v, want 0.25", got)
+	}
+	if got := GetEnv("DEFINED_LEVEL", Level(0)); got != -1 {
+		t.Errorf("GetEnv(Level) = %v, want -1", got)
+	}
+	if _, err := GetEnvE("DEFINED_TIMEOUT", Timeout(0)); err == nil {
+		t.Error("GetEnvE(Timeout) expected an error before RegisterDuration")
+	}
+	RegisterDuration[Timeout]()
+	defer RegisterParser[Timeout](nil)
+	if got := GetEnv("DEFINED_TIMEOUT", Timeout(0)); got != Timeout(5*time.Second) {
+		t.Errorf("GetEnv(Timeout) = %v, want 5s", time.Duration(got))
+	}
+
+	type UserID int64
+	os.Setenv("DEFINED_USER_ID", "1h")
+	defer os.Unsetenv("DEFINED_USER_ID")
+	var parseErr *ParseError
+	if got, err := GetEnvE("DEFINED_USER_ID", UserID(0)); !errors.As(err, &parseErr) {
+		t.Errorf("GetEnvE(UserID) = %v, %v, want a *ParseError", got, err)
+	}
+
+	os.Setenv("DEFINED_PORT", "70000")
+	if _, err := GetEnvE("DEFINED_PORT", Port(80)); err == nil || !strings.Contains(err.Error(), "goenv.Port") {
+		t.Errorf("GetEnvE(Port) error = %v, want an overflow error naming goenv.Port", err)
+	}
+}
+
+func TestGetEnvJSON(t *testing.T) {
+	tmpFile := createTempFile(t, ".yaml", "upstreams:\n  - host: 10.0.0.1\n    port: 8080\n    weight: 3\n  - host: 10.0.0.2\n    port: 8081\n")
+	defer os.Remove(tmpFile)
+	if err := LoadEnv(tmpFile); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("JSON_LITERAL", `{"retries": 3, "backoff": ["1s", "5s"]}`)
+	os.Setenv("JSON_BAD", `[{"host": "a", "port": "eighty"}]`)
+	defer os.Unsetenv("upstreams")
+	defer os.Unsetenv("JSON_LITERAL")
+	defer os.Unsetenv("JSON_BAD")
+
+	type upstream struct {
+		Host   string `json:"host"`
+		Port   int    `json:"port"`
+		Weight int    `json:"weight"`
+	}
+
+	upstreams, err := GetEnvJSON("upstreams", []upstream(nil))
+	want := []upstream{{"10.0.0.1", 8080, 3}, {"10.0.0.2", 8081, 0}}
+	if err != nil || len(upstreams) != 2 || upstreams[0] != want[0] || upstreams[1] != want[1] {
+		t.Errorf("GetEnvJSON() = %+v, %v, want %+v", upstreams, err, want)
+	}
+
+	var policy struct {
+		Retries int      `json:"retries"`
+		Backoff []string `json:"backoff"`
+	}
+	policy, err = GetEnvJSON("JSON_LITERAL", policy)
+	if err != nil || policy.Retries != 3 || len(policy.Backoff) != 2 {
+		t.Errorf("GetEnvJSON() = %+v, %v", policy, err)
+	}
+
+	fallback := []upstream{{Host: "localhost"}}
+	got, err := GetEnvJSON("JSON_BAD", fallback)
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || parseErr.Key != "JSON_BAD" || !strings.Contains(err.Error(), "JSON_BAD") {
+		t.Errorf("GetEnvJSON() error = %v, want a *ParseError naming JSON_BAD", err)
+	}
+	if len(got) != 1 || got[0].Host != "localhost" {
+		t.Errorf("GetEnvJSON() on error = %+v, want the default", got)
+	}
+
+	if got, err := GetEnvJSON("JSON_MISSING", 42); got != 42 || err != nil {
+		t.Errorf("GetEnvJSON() = %v, %v, want the default 42", got, err)
+	}
+}
+
 // Helper function to create temporary files for testing
 func createTempFile(t *testing.T, suffix, content string) string {
 	tmpFile, err := os.CreateTemp("", "goenv_test_*"+suffix)
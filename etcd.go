@@ -0,0 +1,248 @@
+package goenv
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EtcdSource loads every key under an etcd v3 prefix through the etcd JSON
+// gateway. With prefix /config/myapp/, the key /config/myapp/db/host is
+// loaded as db.host.
+type EtcdSource struct {
+	Endpoint string // etcd client URL, defaults to $ETCD_ENDPOINT or http://127.0.0.1:2379
+	Prefix   string // Key directory to read, e.g. "/config/myapp"
+	Username string // Optional username for etcd authentication
+	Password string
+	Decode   bool // Decode values of keys ending in .json, .yaml or .yml
+	Timeout  time.Duration
+	Client   *http.Client
+
+	mu       sync.Mutex
+	revision int64
+}
+
+// NewEtcdSource creates an EtcdSource for a key prefix
+func NewEtcdSource(prefix string) *EtcdSource {
+	endpoint := os.Getenv("ETCD_ENDPOINT")
+	if endpoint == "" {
+		endpoint = "http://127.0.0.1:2379"
+	}
+	return &EtcdSource{Endpoint: endpoint, Prefix: prefix}
+}
+
+// etcdKeyValue is a key-value pair as returned by the JSON gateway
+type etcdKeyValue struct {
+	Key   []byte `json:"key"`
+	Value []byte `json:"value"`
+}
+
+// etcdHeader is the response header returned by the JSON gateway
+type etcdHeader struct {
+	Revision int64 `json:"revision,string"`
+}
+
+// Load reads the prefix and returns its keys
+func (s *EtcdSource) Load(ctx context.Context) (map[string]string, error) {
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = defaultHTTPTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var resp struct {
+		Header etcdHeader     `json:"header"`
+		Kvs    []etcdKeyValue `json:"kvs"`
+	}
+	if err := s.post(ctx, "/v3/kv/range", s.rangeRequest(), &resp); err != nil {
+		return nil, fmt.Errorf("etcd range %s: %w", s.Prefix, err)
+	}
+
+	s.mu.Lock()
+	s.revision = resp.Header.Revision
+	s.mu.Unlock()
+
+	values := make(map[string]string)
+	for _, kv := range resp.Kvs {
+		key := pathToKey(s.Prefix, string(kv.Key))
+		if key == "" {
+			continue
+		}
+
+		if err := decodeValue(key, kv.Value, s.Decode, values); err != nil {
+			return nil, fmt.Errorf("etcd key %s: %w", kv.Key, err)
+		}
+	}
+	return values, nil
+}
+
+// Watch watches the prefix for changes and sends a fresh snapshot of all its
+// keys after every change. The channel is closed when ctx is cancelled, the
+// watch stream ends or the server cancels the watch, for example because its
+// start revision was compacted; call Watch again to resume.
+func (s *EtcdSource) Watch(ctx context.Context) (<-chan map[string]string, error) {
+	s.mu.Lock()
+	revision := s.revision
+	s.mu.Unlock()
+
+	if revision == 0 {
+		if _, err := s.Load(ctx); err != nil {
+			return nil, err
+		}
+		s.mu.Lock()
+		revision = s.revision
+		s.mu.Unlock()
+	}
+
+	create := s.rangeRequest()
+	create["start_revision"] = revision + 1
+	body, err := json.Marshal(map[string]interface{}{"create_request": create})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(s.Endpoint, "/")+"/v3/watch", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(ctx, req); err != nil {
+		return nil, err
+	}
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("etcd watch %s: %w", s.Prefix, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("etcd watch %s: unexpected status %s", s.Prefix, resp.Status)
+	}
+
+	updates := make(chan map[string]string)
+	go func() {
+		defer close(updates)
+		defer resp.Body.Close()
+
+		scanner := bufio.NewScanner(resp.Body)
+		scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+		for scanner.Scan() {
+			var msg struct {
+				Result struct {
+					Canceled        bool              `json:"canceled"`
+					CompactRevision json.Number       `json:"compact_revision"`
+					Events          []json.RawMessage `json:"events"`
+				} `json:"result"`
+			}
+			if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+				continue
+			}
+			// The server cancels the watch when its start revision was
+			// compacted or the watch failed; no further events will follow.
+			if msg.Result.Canceled || (msg.Result.CompactRevision != "" && msg.Result.CompactRevision != "0") {
+				return
+			}
+			if len(msg.Result.Events) == 0 {
+				continue
+			}
+
+			values, err := s.Load(ctx)
+			if err != nil {
+				continue
+			}
+			select {
+			case updates <- values:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return updates, nil
+}
+
+// Revision returns the etcd revision of the last successful Load
+func (s *EtcdSource) Revision() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.revision
+}
+
+// String returns the location of the prefix
+func (s *EtcdSource) String() string {
+	return "etcd:" + s.Prefix
+}
+
+// rangeRequest returns the key range covering the prefix
+func (s *EtcdSource) rangeRequest() map[string]interface{} {
+	if s.Prefix == "" {
+		// A key and range end of "\x00" select every key
+		return map[string]interface{}{"key": []byte{0}, "range_end": []byte{0}}
+	}
+	// Range over the prefix as a directory, so /config/myapp does not also
+	// select /config/myappx
+	prefix := []byte(dirPrefix(s.Prefix))
+	return map[string]interface{}{"key": prefix, "range_end": prefixEnd(prefix)}
+}
+
+// post sends a JSON request to the gateway and decodes the response
+func (s *EtcdSource) post(ctx context.Context, path string, in, out interface{}) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(s.Endpoint, "/")+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if path != "/v3/auth/authenticate" {
+		if err := s.authorize(ctx, req); err != nil {
+			return err
+		}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return doJSON(s.Client, req, out)
+}
+
+// authorize adds an authentication token to req when a username is set
+func (s *EtcdSource) authorize(ctx context.Context, req *http.Request) error {
+	if s.Username == "" {
+		return nil
+	}
+
+	var resp struct {
+		Token string `json:"token"`
+	}
+	if err := s.post(ctx, "/v3/auth/authenticate", map[string]string{"name": s.Username, "password": s.Password}, &resp); err != nil {
+		return fmt.Errorf("etcd authenticate: %w", err)
+	}
+	if resp.Token == "" {
+		return errors.New("etcd authenticate: no token in response")
+	}
+	req.Header.Set("Authorization", resp.Token)
+	return nil
+}
+
+// prefixEnd returns the smallest key greater than every key with the prefix
+func prefixEnd(prefix []byte) []byte {
+	end := append([]byte(nil), prefix...)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	// The prefix is all 0xff bytes, so there is no upper bound
+	return []byte{0}
+}
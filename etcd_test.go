@@ -0,0 +1,183 @@
+package goenv
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// etcdStub serves the range and watch endpoints of the etcd JSON gateway
+type etcdStub struct {
+	mu       sync.Mutex
+	revision int64
+	kvs      map[string]string
+	watchers []chan struct{}
+	cancel   bool // Cancel every watch as compacted
+}
+
+func (e *etcdStub) put(key, value string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.kvs[key] = value
+	e.revision++
+	for _, w := range e.watchers {
+		w <- struct{}{}
+	}
+}
+
+func (e *etcdStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/v3/kv/range":
+		var req struct {
+			Key      []byte `json:"key"`
+			RangeEnd []byte `json:"range_end"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		var kvs []etcdKeyValue
+		for key, value := range e.kvs {
+			if bytes.Compare([]byte(key), req.Key) >= 0 && bytes.Compare([]byte(key), req.RangeEnd) < 0 {
+				kvs = append(kvs, etcdKeyValue{Key: []byte(key), Value: []byte(value)})
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"header": map[string]string{"revision": strconv.FormatInt(e.revision, 10)},
+			"kvs":    kvs,
+		})
+	case "/v3/watch":
+		events := make(chan struct{}, 1)
+		e.mu.Lock()
+		e.watchers = append(e.watchers, events)
+		e.mu.Unlock()
+
+		w.Write([]byte(`{"result": {"created": true}}` + "\n"))
+		if e.cancel {
+			w.Write([]byte(`{"result": {"canceled": true, "compact_revision": "5"}}` + "\n"))
+		}
+		w.(http.Flusher).Flush()
+		for {
+			select {
+			case <-events:
+				w.Write([]byte(`{"result": {"events": [{"kv": {}}]}}` + "\n"))
+				w.(http.Flusher).Flush()
+			case <-r.Context().Done():
+				return
+			}
+		}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestEtcdSource(t *testing.T) {
+	stub := &etcdStub{
+		revision: 7,
+		kvs: map[string]string{
+			"/config/myapp/db/host":     "localhost",
+			"/config/myapp/server.yaml": "port: 8080\ntls:\n  enabled: true\n",
+			"/config/other/db/host":     "ignored",
+			"/config/myappx/db/host":    "sibling",
+		},
+	}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	source := &EtcdSource{Endpoint: server.URL, Prefix: "/config/myapp", Decode: true}
+	values, err := source.Load(context.Background())
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	want := map[string]string{
+		"db.host":            "localhost",
+		"server.port":        "8080",
+		"server.tls.enabled": "true",
+	}
+	for key, value := range want {
+		if got := values[key]; got != value {
+			t.Errorf("Load()[%s] = %v, want %v", key, got, value)
+		}
+	}
+	if len(values) != len(want) {
+		t.Errorf("Load() returned %d keys, want %d: %v", len(values), len(want), values)
+	}
+	if got := source.Revision(); got != 7 {
+		t.Errorf("Revision() = %d, want 7", got)
+	}
+}
+
+func TestEtcdSource_Watch(t *testing.T) {
+	stub := &etcdStub{revision: 1, kvs: map[string]string{"/config/myapp/log/level": "info"}}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	source := &EtcdSource{Endpoint: server.URL, Prefix: "/config/myapp/"}
+	updates, err := source.Watch(ctx)
+	if err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+
+	stub.put("/config/myapp/log/level", "debug")
+
+	select {
+	case values := <-updates:
+		if got := values["log.level"]; got != "debug" {
+			t.Errorf("log.level = %v, want debug", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Watch() did not deliver an update")
+	}
+
+	cancel()
+	for range updates {
+	}
+}
+
+func TestPrefixEnd(t *testing.T) {
+	tests := []struct {
+		prefix string
+		want   string
+	}{
+		{"/config/", "/config0"},
+		{"a\xff", "b"},
+		{"\xff\xff", "\x00"},
+	}
+
+	for _, tt := range tests {
+		if got := string(prefixEnd([]byte(tt.prefix))); got != tt.want {
+			t.Errorf("prefixEnd(%q) = %q, want %q", tt.prefix, got, tt.want)
+		}
+	}
+}
+
+func TestEtcdSource_WatchCanceled(t *testing.T) {
+	stub := &etcdStub{revision: 1, kvs: map[string]string{"/config/myapp/log/level": "info"}, cancel: true}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	source := &EtcdSource{Endpoint: server.URL, Prefix: "/config/myapp/"}
+	updates, err := source.Watch(context.Background())
+	if err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+
+	select {
+	case values, ok := <-updates:
+		if ok {
+			t.Errorf("Watch() sent %v after the watch was canceled", values)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Watch() did not close the channel after the watch was canceled")
+	}
+}
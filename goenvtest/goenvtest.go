@@ -0,0 +1,171 @@
+// Package goenvtest provides helpers for hermetic tests of code that reads
+// configuration through goenv.
+//
+// Every helper that changes the process environment takes a snapshot first
+// and restores it in t.Cleanup, so variables loaded from fixtures never leak
+// into other tests. Like t.Setenv, these helpers must not be used in parallel
+// tests; use an isolated goenv.Config there instead.
+package goenvtest
+
+import (
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"go.risoftinc.com/goenv"
+)
+
+// Snapshot records the current process environment and restores it when the
+// test and all its subtests complete. Files loaded into goenv.Default during
+// the test are forgotten as well, so a later Reload does not read them again
+// and Origin no longer reports them.
+func Snapshot(tb testing.TB) {
+	tb.Helper()
+
+	saved := environ()
+	restore := goenv.Default().Checkpoint()
+	tb.Cleanup(func() {
+		for key := range environ() {
+			if _, ok := saved[key]; !ok {
+				os.Unsetenv(key)
+			}
+		}
+		for key, value := range saved {
+			if current, ok := os.LookupEnv(key); !ok || current != value {
+				os.Setenv(key, value)
+			}
+		}
+		restore()
+	})
+}
+
+// Load loads fixture files with goenv.LoadEnv and fails the test if none of
+// them loads. The environment is restored when the test completes.
+func Load(tb testing.TB, files ...string) {
+	tb.Helper()
+
+	Snapshot(tb)
+	if err := goenv.LoadEnv(files...); err != nil {
+		tb.Fatalf("goenvtest: %v", err)
+	}
+}
+
+// Set sets environment variables for the duration of the test
+func Set(tb testing.TB, values map[string]string) {
+	tb.Helper()
+
+	Snapshot(tb)
+	for key, value := range values {
+		os.Setenv(key, value)
+	}
+}
+
+// Unset removes environment variables for the duration of the test
+func Unset(tb testing.TB, keys ...string) {
+	tb.Helper()
+
+	Snapshot(tb)
+	for _, key := range keys {
+		os.Unsetenv(key)
+	}
+}
+
+// AssertEnv reports an error if the raw value of key differs from want
+func AssertEnv(tb testing.TB, key, want string) {
+	tb.Helper()
+
+	got, ok := os.LookupEnv(key)
+	if !ok {
+		tb.Errorf("goenvtest: %s is not set, want %q", key, want)
+	} else if got != want {
+		tb.Errorf("goenvtest: %s = %q, want %q", key, got, want)
+	}
+}
+
+// AssertKeys reports an error listing every key that is not set
+func AssertKeys(tb testing.TB, keys ...string) {
+	tb.Helper()
+
+	var missing []string
+	for _, key := range keys {
+		if _, ok := os.LookupEnv(key); !ok {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		tb.Errorf("goenvtest: missing keys: %s", strings.Join(missing, ", "))
+	}
+}
+
+// AssertUnset reports an error listing every key that is set
+func AssertUnset(tb testing.TB, keys ...string) {
+	tb.Helper()
+
+	var set []string
+	for _, key := range keys {
+		if _, ok := os.LookupEnv(key); ok {
+			set = append(set, key)
+		}
+	}
+	if len(set) > 0 {
+		tb.Errorf("goenvtest: unexpected keys: %s", strings.Join(set, ", "))
+	}
+}
+
+// AssertValue reports an error if key, converted with goenv.GetEnv to the type
+// of want, differs from want
+func AssertValue[T any](tb testing.TB, key string, want T) {
+	tb.Helper()
+
+	raw, ok := os.LookupEnv(key)
+	if !ok {
+		tb.Errorf("goenvtest: %s is not set, want %v", key, want)
+		return
+	}
+
+	var zero T
+	if got := goenv.GetEnv(key, zero); !reflect.DeepEqual(got, want) {
+		tb.Errorf("goenvtest: %s = %v (raw %q), want %v", key, got, raw, want)
+	}
+}
+
+// AssertParses parses file into an isolated goenv.Config, without touching
+// the process environment, and reports every key whose value differs from
+// want. Keys that are loaded but not listed in want are ignored.
+func AssertParses(tb testing.TB, file string, want map[string]string) {
+	tb.Helper()
+
+	cfg := goenv.NewConfig()
+	if err := cfg.Load(file); err != nil {
+		tb.Errorf("goenvtest: %v", err)
+		return
+	}
+
+	keys := make([]string, 0, len(want))
+	for key := range want {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		got, ok := cfg.Lookup(key)
+		if !ok {
+			tb.Errorf("goenvtest: %s: %s is not set, want %q", file, key, want[key])
+		} else if got != want[key] {
+			tb.Errorf("goenvtest: %s: %s = %q, want %q", file, key, got, want[key])
+		}
+	}
+}
+
+// environ returns the process environment as a map
+func environ() map[string]string {
+	env := make(map[string]string)
+	for _, kv := range os.Environ() {
+		if key, value, ok := strings.Cut(kv, "="); ok {
+			env[key] = value
+		}
+	}
+	return env
+}
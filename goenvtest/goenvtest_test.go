@@ -0,0 +1,128 @@
+package goenvtest
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.risoftinc.com/goenv"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad_RestoresEnvironment(t *testing.T) {
+	file := writeFile(t, "fixture.env", "GOENVTEST_PORT=9090\nGOENVTEST_HOST=localhost\n")
+	os.Setenv("GOENVTEST_HOST", "original")
+	defer os.Unsetenv("GOENVTEST_HOST")
+
+	t.Run("load", func(t *testing.T) {
+		Load(t, file)
+		AssertEnv(t, "GOENVTEST_HOST", "localhost")
+		AssertValue(t, "GOENVTEST_PORT", 9090)
+		AssertKeys(t, "GOENVTEST_PORT", "GOENVTEST_HOST")
+	})
+
+	if got := os.Getenv("GOENVTEST_HOST"); got != "original" {
+		t.Errorf("GOENVTEST_HOST = %q after cleanup, want original", got)
+	}
+	if _, ok := os.LookupEnv("GOENVTEST_PORT"); ok {
+		t.Error("GOENVTEST_PORT is still set after cleanup")
+	}
+}
+
+func TestLoad_ForgetsFixture(t *testing.T) {
+	t.Run("load", func(t *testing.T) {
+		Load(t, writeFile(t, "fixture.env", "GOENVTEST_RELOADED=fixture\n"))
+	})
+
+	if _, err := goenv.Default().Reload(context.Background()); err != nil {
+		t.Fatalf("Reload() after cleanup error = %v", err)
+	}
+	AssertUnset(t, "GOENVTEST_RELOADED")
+	if got := goenv.Default().Origin("GOENVTEST_RELOADED"); got != "" {
+		t.Errorf("Origin() after cleanup = %q, want empty", got)
+	}
+
+	t.Run("set", func(t *testing.T) {
+		Load(t, writeFile(t, "fixture.env", "GOENVTEST_RELOADED=fixture\n"))
+		Set(t, map[string]string{"GOENVTEST_RELOADED": "direct"})
+	})
+	os.Setenv("GOENVTEST_RELOADED", "process")
+	defer os.Unsetenv("GOENVTEST_RELOADED")
+	if got := goenv.Default().Origin("GOENVTEST_RELOADED"); got != "environment" {
+		t.Errorf("Origin() after cleanup = %q, want environment", got)
+	}
+}
+
+func TestSetAndUnset(t *testing.T) {
+	os.Setenv("GOENVTEST_REMOVED", "value")
+	defer os.Unsetenv("GOENVTEST_REMOVED")
+
+	t.Run("set", func(t *testing.T) {
+		Set(t, map[string]string{"GOENVTEST_TIMEOUT": "5s", "GOENVTEST_DEBUG": "true"})
+		Unset(t, "GOENVTEST_REMOVED")
+
+		AssertValue(t, "GOENVTEST_TIMEOUT", 5*time.Second)
+		AssertValue(t, "GOENVTEST_DEBUG", true)
+		AssertUnset(t, "GOENVTEST_REMOVED")
+	})
+
+	AssertEnv(t, "GOENVTEST_REMOVED", "value")
+	AssertUnset(t, "GOENVTEST_TIMEOUT", "GOENVTEST_DEBUG")
+}
+
+func TestAssertParses(t *testing.T) {
+	file := writeFile(t, "fixture.yaml", "server:\n  port: 8080\n  host: example.com\n")
+
+	AssertParses(t, file, map[string]string{
+		"server.port": "8080",
+		"server.host": "example.com",
+	})
+	AssertUnset(t, "server.port")
+}
+
+func TestAssertions_Fail(t *testing.T) {
+	Set(t, map[string]string{"GOENVTEST_NUMBER": "not-a-number"})
+	file := writeFile(t, "fixture.env", "A=1\n")
+
+	tests := []struct {
+		name   string
+		assert func(tb testing.TB)
+	}{
+		{"AssertEnv value", func(tb testing.TB) { AssertEnv(tb, "GOENVTEST_NUMBER", "1") }},
+		{"AssertEnv unset", func(tb testing.TB) { AssertEnv(tb, "GOENVTEST_MISSING", "1") }},
+		{"AssertKeys", func(tb testing.TB) { AssertKeys(tb, "GOENVTEST_MISSING") }},
+		{"AssertUnset", func(tb testing.TB) { AssertUnset(tb, "GOENVTEST_NUMBER") }},
+		{"AssertValue", func(tb testing.TB) { AssertValue(tb, "GOENVTEST_NUMBER", 1) }},
+		{"AssertParses value", func(tb testing.TB) { AssertParses(tb, file, map[string]string{"A": "2"}) }},
+		{"AssertParses missing", func(tb testing.TB) { AssertParses(tb, file, map[string]string{"B": "1"}) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &recorder{TB: t}
+			tt.assert(rec)
+			if !rec.failed {
+				t.Errorf("%s did not report a failure", tt.name)
+			}
+		})
+	}
+}
+
+// recorder records failures instead of failing the test
+type recorder struct {
+	testing.TB
+	failed bool
+}
+
+func (r *recorder) Errorf(format string, args ...any) { r.failed = true }
@@ -0,0 +1,220 @@
+package goenv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultHTTPTimeout    = 10 * time.Second
+	defaultHTTPRetryDelay = 500 * time.Millisecond
+)
+
+// HTTPSource loads values from a JSON, YAML or key-value document served
+// over HTTP(S). Responses are cached and revalidated with ETag and
+// Last-Modified, so an unchanged document is not downloaded again.
+//
+// Scheme values such as file: and env: are kept literally, so that a remote
+// document cannot read local files or variables, unless Resolve is set.
+type HTTPSource struct {
+	URL        string
+	Format     FileFormat    // FormatAuto uses the Content-Type, then the URL extension
+	Header     http.Header   // Extra request headers, e.g. Authorization
+	Timeout    time.Duration // Timeout for each attempt, defaults to 10s
+	Retries    int           // Number of retries after a failed attempt
+	RetryDelay time.Duration // Delay before the first retry, doubled after each one
+	Client     *http.Client  // Defaults to http.DefaultClient
+	Resolve    bool          // Resolve scheme values like a local file
+
+	mu           sync.Mutex
+	etag         string
+	lastModified string
+	cached       map[string]string
+}
+
+// NewHTTPSource creates an HTTPSource that auto-detects the document format
+func NewHTTPSource(url string) *HTTPSource {
+	return &HTTPSource{URL: url, Format: FormatAuto, Header: make(http.Header)}
+}
+
+// Load fetches and parses the document, retrying on network errors and
+// server errors. A 304 Not Modified response returns the cached values.
+func (s *HTTPSource) Load(ctx context.Context) (map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delay := s.RetryDelay
+	if delay <= 0 {
+		delay = defaultHTTPRetryDelay
+	}
+
+	var err error
+	for attempt := 0; attempt <= s.Retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(delay):
+			}
+			delay *= 2
+		}
+
+		var values map[string]string
+		var retry bool
+		values, retry, err = s.fetch(ctx)
+		if err == nil {
+			return copyValues(values), nil
+		}
+		if !retry {
+			break
+		}
+	}
+	return nil, err
+}
+
+// fetch performs a single request. It reports whether a failure is worth
+// retrying.
+func (s *HTTPSource) fetch(ctx context.Context) (map[string]string, bool, error) {
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = defaultHTTPTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	for key, values := range s.Header {
+		req.Header[key] = values
+	}
+	if s.cached != nil {
+		if s.etag != "" {
+			req.Header.Set("If-None-Match", s.etag)
+		}
+		if s.lastModified != "" {
+			req.Header.Set("If-Modified-Since", s.lastModified)
+		}
+	}
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, true, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && s.cached != nil:
+		return s.cached, false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return nil, true, fmt.Errorf("unexpected status %s", resp.Status)
+	case resp.StatusCode != http.StatusOK:
+		return nil, false, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, true, err
+	}
+
+	format := s.Format
+	if format == FormatAuto {
+		format = detectHTTPFormat(resp.Header.Get("Content-Type"), s.URL)
+	}
+
+	values, err := parseFormat(format, data)
+	if err != nil {
+		return nil, false, err
+	}
+	if s.Resolve {
+		if err := resolveValues(values, contextLookup(ctx)); err != nil {
+			return nil, false, err
+		}
+	}
+
+	s.cached = values
+	s.etag = resp.Header.Get("ETag")
+	s.lastModified = resp.Header.Get("Last-Modified")
+	return values, false, nil
+}
+
+// String returns the URL of the source
+func (s *HTTPSource) String() string {
+	return s.URL
+}
+
+// detectHTTPFormat detects the format from a Content-Type header, falling
+// back to the extension of the URL path
+func detectHTTPFormat(contentType, rawURL string) FileFormat {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		switch {
+		case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+			return FormatJSON
+		case strings.Contains(mediaType, "yaml"):
+			return FormatYAML
+		}
+	}
+
+	if u, err := url.Parse(rawURL); err == nil {
+		return detectFormat(path.Base(u.Path))
+	}
+	return detectFormat(rawURL)
+}
+
+// isURL reports whether name is an HTTP(S) URL rather than a file path
+func isURL(name string) bool {
+	lower := strings.ToLower(name)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
+
+// doJSON sends req and decodes a successful JSON response into out. The
+// response body is included in the error for non-2xx statuses.
+func doJSON(client *http.Client, req *http.Request, out interface{}) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return &statusError{Code: resp.StatusCode, Status: resp.Status, Body: strings.TrimSpace(string(body))}
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// statusError reports an unexpected HTTP response status
+type statusError struct {
+	Code   int
+	Status string
+	Body   string
+}
+
+func (e *statusError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("unexpected status %s", e.Status)
+	}
+	return fmt.Sprintf("unexpected status %s: %s", e.Status, e.Body)
+}
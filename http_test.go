@@ -0,0 +1,162 @@
+package goenv
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHTTPSource_ETag(t *testing.T) {
+	var requests, notModified int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(&notModified, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Write([]byte(`{"app": {"name": "remote"}}`))
+	}))
+	defer server.Close()
+
+	source := NewHTTPSource(server.URL + "/config")
+	source.Header.Set("Authorization", "Bearer secret")
+
+	for i := 0; i < 2; i++ {
+		values, err := source.Load(context.Background())
+		if err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		if got := values["app.name"]; got != "remote" {
+			t.Errorf("app.name = %v, want remote", got)
+		}
+	}
+
+	if atomic.LoadInt32(&requests) != 2 || atomic.LoadInt32(&notModified) != 1 {
+		t.Errorf("requests = %d, not modified = %d, want 2 and 1", requests, notModified)
+	}
+}
+
+func TestHTTPSource_Retries(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("app:\n  name: retried\n"))
+	}))
+	defer server.Close()
+
+	source := NewHTTPSource(server.URL + "/config.yaml")
+	source.Retries = 2
+	source.RetryDelay = time.Millisecond
+
+	values, err := source.Load(context.Background())
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got := values["app.name"]; got != "retried" {
+		t.Errorf("app.name = %v, want retried", got)
+	}
+
+	source = NewHTTPSource(server.URL + "/missing")
+	atomic.StoreInt32(&requests, 0)
+	source.Retries = 1
+	source.RetryDelay = time.Millisecond
+	if _, err := source.Load(context.Background()); err == nil {
+		t.Error("Load() expected error after exhausting retries")
+	}
+}
+
+func TestHTTPSource_Resolve(t *testing.T) {
+	secret := createTempFile(t, ".txt", "local secret")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("SECRET=file:" + secret + "\n"))
+	}))
+	defer server.Close()
+
+	source := NewHTTPSource(server.URL + "/config.env")
+	values, err := source.Load(context.Background())
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got, want := values["SECRET"], "file:"+secret; got != want {
+		t.Errorf("SECRET = %v, want %v", got, want)
+	}
+
+	source = NewHTTPSource(server.URL + "/config.env")
+	source.Resolve = true
+	values, err = source.Load(context.Background())
+	if err != nil {
+		t.Fatalf("Load() with Resolve error = %v", err)
+	}
+	if got := values["SECRET"]; got != "local secret" {
+		t.Errorf("SECRET with Resolve = %v, want local secret", got)
+	}
+}
+
+func TestHTTPSource_Timeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+
+	source := NewHTTPSource(server.URL)
+	source.Timeout = 10 * time.Millisecond
+
+	if _, err := source.Load(context.Background()); err == nil {
+		t.Error("Load() expected timeout error")
+	}
+}
+
+func TestDetectHTTPFormat(t *testing.T) {
+	tests := []struct {
+		contentType string
+		url         string
+		expected    FileFormat
+	}{
+		{"application/json", "https://config.internal/app", FormatJSON},
+		{"application/vnd.api+json", "https://config.internal/app", FormatJSON},
+		{"application/yaml", "https://config.internal/app", FormatYAML},
+		{"text/plain", "https://config.internal/app.yaml?v=1", FormatYAML},
+		{"", "https://config.internal/app.json", FormatJSON},
+		{"", "https://config.internal/app", FormatKeyValue},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.contentType+" "+tt.url, func(t *testing.T) {
+			if got := detectHTTPFormat(tt.contentType, tt.url); got != tt.expected {
+				t.Errorf("detectHTTPFormat() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLoadEnv_URL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("REMOTE_NAME=from-url\n"))
+	}))
+	defer server.Close()
+	defer os.Unsetenv("REMOTE_NAME")
+
+	if err := LoadEnv("non_existent.env", server.URL+"/app.env"); err != nil {
+		t.Fatalf("LoadEnv() error = %v", err)
+	}
+	if got := os.Getenv("REMOTE_NAME"); got != "from-url" {
+		t.Errorf("REMOTE_NAME = %v, want from-url", got)
+	}
+}
@@ -0,0 +1,177 @@
+package goenv
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// DefaultListSeparator separates the elements of delimited slice and map
+// values
+const DefaultListSeparator = ","
+
+var (
+	listSeparatorMu sync.RWMutex
+	listSeparator   = DefaultListSeparator
+)
+
+// SetListSeparator sets the separator of delimited slice and map values used
+// by GetEnv and the other typed helpers. Bind fields can override it with a
+// sep tag. An empty separator restores DefaultListSeparator.
+func SetListSeparator(sep string) {
+	listSeparatorMu.Lock()
+	defer listSeparatorMu.Unlock()
+
+	if sep == "" {
+		sep = DefaultListSeparator
+	}
+	listSeparator = sep
+}
+
+// currentListSeparator returns the separator set with SetListSeparator
+func currentListSeparator() string {
+	listSeparatorMu.RLock()
+	defer listSeparatorMu.RUnlock()
+	return listSeparator
+}
+
+// parseSlice parses a JSON array, or elements separated by sep, into a slice
+// of type typ
+func parseSlice(raw string, typ reflect.Type, sep string) (reflect.Value, error) {
+	var elems []string
+	if strings.HasPrefix(strings.TrimSpace(raw), "[") {
+		var items []json.RawMessage
+		if err := json.Unmarshal([]byte(raw), &items); err != nil {
+			return reflect.Value{}, err
+		}
+		for _, item := range items {
+			elems = append(elems, jsonElement(item))
+		}
+	} else {
+		tokens, err := splitList(raw, sep)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		for _, token := range tokens {
+			elems = append(elems, unquote(token))
+		}
+	}
+
+	slice := reflect.MakeSlice(typ, 0, len(elems))
+	for i, elem := range elems {
+		parsed, err := parseValueSep(elem, typ.Elem(), sep)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("element %d: %w", i, err)
+		}
+		slice = reflect.Append(slice, parsed)
+	}
+	return slice, nil
+}
+
+// parseMap parses a JSON object, or key=value pairs separated by sep, into a
+// map of type typ
+func parseMap(raw string, typ reflect.Type, sep string) (reflect.Value, error) {
+	var keys, values []string
+	if strings.HasPrefix(strings.TrimSpace(raw), "{") {
+		var items map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(raw), &items); err != nil {
+			return reflect.Value{}, err
+		}
+		for key, item := range items {
+			keys = append(keys, key)
+			values = append(values, jsonElement(item))
+		}
+	} else {
+		tokens, err := splitList(raw, sep)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		for _, token := range tokens {
+			key, value, ok := strings.Cut(token, "=")
+			if !ok {
+				return reflect.Value{}, fmt.Errorf("entry %q is not in key=value form", strings.TrimSpace(token))
+			}
+			keys = append(keys, unquote(key))
+			values = append(values, unquote(value))
+		}
+	}
+
+	m := reflect.MakeMapWithSize(typ, len(keys))
+	for i, key := range keys {
+		parsedKey, err := parseValueSep(key, typ.Key(), sep)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("key %q: %w", key, err)
+		}
+		parsedValue, err := parseValueSep(values[i], typ.Elem(), sep)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("key %q: %w", key, err)
+		}
+		m.SetMapIndex(parsedKey, parsedValue)
+	}
+	return m, nil
+}
+
+// jsonElement returns a JSON string element unquoted and any other element,
+// such as a number or a nested array, as its JSON text
+func jsonElement(item json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(item, &s); err == nil {
+		return s
+	}
+	return string(item)
+}
+
+// splitList splits s at every sep outside of single or double quotes. The
+// quotes are kept so that map entries can be split into key and value first.
+func splitList(s, sep string) ([]string, error) {
+	var tokens []string
+	var quote byte
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case strings.HasPrefix(s[i:], sep):
+			tokens = append(tokens, s[start:i])
+			i += len(sep) - 1
+			start = i + 1
+		}
+	}
+	if quote != 0 {
+		return nil, errors.New("unterminated quote")
+	}
+	return append(tokens, s[start:]), nil
+}
+
+// unquote trims a list token and removes surrounding quotes. Double quoted
+// tokens may escape quotes and backslashes with a backslash.
+func unquote(token string) string {
+	token = strings.TrimSpace(token)
+	if len(token) < 2 || token[0] != token[len(token)-1] {
+		return token
+	}
+
+	switch token[0] {
+	case '\'':
+		return token[1 : len(token)-1]
+	case '"':
+		var b strings.Builder
+		for i := 1; i < len(token)-1; i++ {
+			if token[i] == '\\' && i+1 < len(token)-1 {
+				i++
+			}
+			b.WriteByte(token[i])
+		}
+		return b.String()
+	}
+	return token
+}
@@ -0,0 +1,296 @@
+package goenv
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// errUnsupportedType is returned by parseValue for types it cannot parse
+var errUnsupportedType = errors.New("unsupported type")
+
+// ParseError reports a value that cannot be converted to the requested type
+type ParseError struct {
+	Key   string       // Key of the value
+	Value string       // Raw value
+	Type  reflect.Type // Requested type
+	Err   error        // Underlying error, such as strconv.ErrSyntax
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s: invalid value %q for %s: %v", e.Key, e.Value, e.Type, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// parseKey converts the raw value of key into a value of type typ, returning
+// a *ParseError when it cannot be parsed
+func parseKey(key, raw string, typ reflect.Type) (reflect.Value, error) {
+	return parseKeySep(key, raw, typ, currentListSeparator())
+}
+
+// parseKeySep is parseKey with an explicit list separator
+func parseKeySep(key, raw string, typ reflect.Type, sep string) (reflect.Value, error) {
+	parsed, err := parseValueSep(raw, typ, sep)
+	if err != nil {
+		if numErr, ok := err.(*strconv.NumError); ok {
+			err = numErr.Err
+		}
+		return reflect.Value{}, &ParseError{Key: key, Value: raw, Type: typ, Err: err}
+	}
+	return parsed, nil
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// typeParser converts a raw value into a value of a registered type
+type typeParser func(raw string) (reflect.Value, error)
+
+var (
+	parsersMu sync.RWMutex
+	parsers   = make(map[reflect.Type]typeParser)
+)
+
+// RegisterParser registers the conversion used for T by GetEnv, Bind and the
+// other typed helpers, for third-party types that do not implement
+// encoding.TextUnmarshaler. A registered parser takes precedence over the
+// built-in rules, and a nil parser removes it.
+func RegisterParser[T any](parse func(raw string) (T, error)) {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+
+	parsersMu.Lock()
+	defer parsersMu.Unlock()
+
+	if parse == nil {
+		delete(parsers, typ)
+		return
+	}
+	parsers[typ] = func(raw string) (reflect.Value, error) {
+		parsed, err := parse(raw)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(&parsed).Elem(), nil
+	}
+}
+
+// RegisterJSONParser makes GetEnv, Bind and the other typed helpers decode
+// values of T as JSON, calling its UnmarshalJSON method if it implements
+// json.Unmarshaler. This takes precedence over encoding.TextUnmarshaler.
+func RegisterJSONParser[T any]() {
+	RegisterParser(func(raw string) (T, error) {
+		var parsed T
+		err := json.Unmarshal([]byte(raw), &parsed)
+		return parsed, err
+	})
+}
+
+// RegisterDuration parses values of T with time.ParseDuration, for defined
+// types over time.Duration such as type Timeout time.Duration. Reflection
+// cannot tell them apart from other defined int64 types, so without it they
+// are parsed as integers.
+func RegisterDuration[T ~int64]() {
+	RegisterParser(func(raw string) (T, error) {
+		parsed, err := time.ParseDuration(raw)
+		return T(parsed), err
+	})
+}
+
+// registeredParser returns the parser registered for typ
+func registeredParser(typ reflect.Type) (typeParser, bool) {
+	parsersMu.RLock()
+	defer parsersMu.RUnlock()
+	parse, ok := parsers[typ]
+	return parse, ok
+}
+
+// parseValue converts raw into a value of type typ using the conversion rules
+// of GetEnv, in order of precedence:
+//
+//   - a parser registered with RegisterParser, RegisterJSONParser or
+//     RegisterDuration
+//   - encoding.TextUnmarshaler, implemented by the type or a pointer to it
+//   - time.Duration
+//   - the underlying kind of typ, so defined types such as type Port uint16
+//     are supported too; defined types over time.Duration are integers
+//     unless registered with RegisterDuration
+//   - slices and maps of supported types, see parseSlice and parseMap
+func parseValue(raw string, typ reflect.Type) (reflect.Value, error) {
+	return parseValueSep(raw, typ, currentListSeparator())
+}
+
+// parseValueSep is parseValue with an explicit list separator
+func parseValueSep(raw string, typ reflect.Type, sep string) (reflect.Value, error) {
+	if parse, ok := registeredParser(typ); ok {
+		return parse(raw)
+	}
+
+	if reflect.PointerTo(typ).Implements(textUnmarshalerType) {
+		ptr := reflect.New(typ)
+		if err := ptr.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(raw)); err != nil {
+			return reflect.Value{}, err
+		}
+		return ptr.Elem(), nil
+	}
+	if typ.Kind() == reflect.Pointer && typ.Implements(textUnmarshalerType) {
+		ptr := reflect.New(typ.Elem())
+		if err := ptr.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(raw)); err != nil {
+			return reflect.Value{}, err
+		}
+		return ptr, nil
+	}
+
+	value := reflect.New(typ).Elem()
+	if typ == durationType {
+		parsed, err := time.ParseDuration(raw)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		value.SetInt(int64(parsed))
+		return value, nil
+	}
+
+	switch typ.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		value.SetBool(parsed)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, err := parseInt(raw, typ.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		value.SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		parsed, err := parseUint(raw, typ.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		value.SetUint(parsed)
+	case reflect.Float32, reflect.Float64:
+		parsed, err := parseFloat(raw, typ.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		value.SetFloat(parsed)
+	case reflect.Complex64, reflect.Complex128:
+		parsed, err := strconv.ParseComplex(raw, typ.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		value.SetComplex(parsed)
+	case reflect.Slice:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			// Byte slices hold the raw value rather than a list of numbers
+			value.SetBytes([]byte(raw))
+			return value, nil
+		}
+		if !canParse(typ.Elem()) {
+			return reflect.Value{}, errUnsupportedType
+		}
+		return parseSlice(raw, typ, sep)
+	case reflect.Map:
+		if !canParse(typ.Key()) || !canParse(typ.Elem()) {
+			return reflect.Value{}, errUnsupportedType
+		}
+		return parseMap(raw, typ, sep)
+	default:
+		return reflect.Value{}, errUnsupportedType
+	}
+	return value, nil
+}
+
+// parseInt parses a signed integer of the given bit size. It accepts
+// underscores between digits and 0x, 0o and 0b prefixes, but unlike Go
+// literals a leading zero without a prefix is decimal, so 0755 is 755.
+func parseInt(s string, bits int) (int64, error) {
+	return strconv.ParseInt(decimalLeadingZeros(s), 0, bits)
+}
+
+// parseUint parses an unsigned integer like parseInt
+func parseUint(s string, bits int) (uint64, error) {
+	return strconv.ParseUint(decimalLeadingZeros(s), 0, bits)
+}
+
+// parseFloat parses a float of the given bit size. Integers with a 0x, 0o or
+// 0b prefix are accepted as for integer types, while hexadecimal floats with a
+// fraction need a p exponent as in Go.
+func parseFloat(s string, bits int) (float64, error) {
+	parsed, err := strconv.ParseFloat(s, bits)
+	if err != nil && hasBasePrefix(s) && !strings.ContainsAny(s, ".pP") {
+		if n, intErr := strconv.ParseInt(s, 0, 64); intErr == nil {
+			return float64(n), nil
+		}
+	}
+	return parsed, err
+}
+
+// hasBasePrefix reports whether a number, after its sign, starts with a 0x,
+// 0o or 0b prefix
+func hasBasePrefix(s string) bool {
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		s = s[1:]
+	}
+	return len(s) > 2 && s[0] == '0' && strings.ContainsRune("xXoObB", rune(s[1]))
+}
+
+// decimalLeadingZeros removes the leading zeros of an integer without a base
+// prefix, so that base 0 parsing does not treat it as octal
+func decimalLeadingZeros(s string) string {
+	sign := ""
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		sign, s = s[:1], s[1:]
+	}
+	if len(s) < 2 || s[0] != '0' {
+		return sign + s
+	}
+	switch s[1] {
+	case 'x', 'X', 'o', 'O', 'b', 'B':
+		return sign + s
+	}
+
+	// Underscores may follow the leading zeros, as in 0_755
+	if s = strings.TrimLeft(s, "0_"); s == "" {
+		s = "0"
+	}
+	return sign + s
+}
+
+// canParse reports whether parseValue supports typ
+func canParse(typ reflect.Type) bool {
+	if _, ok := registeredParser(typ); ok {
+		return true
+	}
+	if reflect.PointerTo(typ).Implements(textUnmarshalerType) ||
+		(typ.Kind() == reflect.Pointer && typ.Implements(textUnmarshalerType)) {
+		return true
+	}
+
+	switch typ.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return true
+	case reflect.Slice:
+		return canParse(typ.Elem())
+	case reflect.Map:
+		return canParse(typ.Key()) && canParse(typ.Elem())
+	}
+	return false
+}
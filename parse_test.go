@@ -0,0 +1,190 @@
+package goenv
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/netip"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// color is an enum that implements encoding.TextUnmarshaler
+type color int
+
+const (
+	red color = iota + 1
+	green
+)
+
+func (c *color) UnmarshalText(text []byte) error {
+	switch strings.ToLower(string(text)) {
+	case "red":
+		*c = red
+	case "green":
+		*c = green
+	default:
+		return fmt.Errorf("unknown color %q", text)
+	}
+	return nil
+}
+
+// endpoint implements both encoding.TextUnmarshaler and json.Unmarshaler
+type endpoint struct {
+	Host string
+	Port int
+}
+
+func (e *endpoint) UnmarshalText(text []byte) error {
+	e.Host = string(text)
+	return nil
+}
+
+func (e *endpoint) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Host string `json:"host"`
+		Port int    `json:"port"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	e.Host, e.Port = raw.Host, raw.Port
+	return nil
+}
+
+func TestGetE_TextUnmarshaler(t *testing.T) {
+	c := NewConfig()
+	c.Set("ADDR", "10.0.0.1")
+	c.Set("LEVEL", "warn")
+	c.Set("BIG", "123456789012345678901234567890")
+	c.Set("COLOR", "Green")
+	c.Set("BAD_COLOR", "blue")
+
+	if got, err := GetE(c, "ADDR", netip.Addr{}); err != nil || got != netip.MustParseAddr("10.0.0.1") {
+		t.Errorf("GetE(netip.Addr) = %v, %v", got, err)
+	}
+	if got, err := GetE(c, "LEVEL", slog.LevelInfo); err != nil || got != slog.LevelWarn {
+		t.Errorf("GetE(slog.Level) = %v, %v", got, err)
+	}
+	if got, err := GetE[*big.Int](c, "BIG", nil); err != nil || got.String() != "123456789012345678901234567890" {
+		t.Errorf("GetE(*big.Int) = %v, %v", got, err)
+	}
+	if got := Get(c, "COLOR", red); got != green {
+		t.Errorf("Get(color) = %v, want green", got)
+	}
+
+	_, err := GetE(c, "BAD_COLOR", red)
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || !strings.Contains(err.Error(), `unknown color "blue"`) {
+		t.Errorf("GetE(color) error = %v, want a *ParseError", err)
+	}
+}
+
+func TestRegisterParser(t *testing.T) {
+	RegisterParser(func(raw string) (*url.URL, error) {
+		return url.Parse(raw)
+	})
+	defer RegisterParser[*url.URL](nil)
+
+	c := NewConfig()
+	c.Set("API_URL", "https://api.example.com/v1")
+	c.Set("UPSTREAM", `{"host": "10.0.0.2", "port": 8080}`)
+
+	if got, err := GetE[*url.URL](c, "API_URL", nil); err != nil || got.Host != "api.example.com" {
+		t.Errorf("GetE(*url.URL) = %v, %v", got, err)
+	}
+
+	// Text unmarshaling is used until JSON decoding is requested
+	if got := Get(c, "UPSTREAM", endpoint{}); got.Port != 0 {
+		t.Errorf("Get(endpoint) = %+v, want text unmarshaling", got)
+	}
+	RegisterJSONParser[endpoint]()
+	defer RegisterParser[endpoint](nil)
+	if got := Get(c, "UPSTREAM", endpoint{}); got != (endpoint{Host: "10.0.0.2", Port: 8080}) {
+		t.Errorf("Get(endpoint) = %+v, want JSON unmarshaling", got)
+	}
+
+	var cfg struct {
+		API      *url.URL   `env:"API_URL"`
+		Upstream endpoint   `env:"UPSTREAM"`
+		Addr     netip.Addr `env:"ADDR" default:"127.0.0.1"`
+	}
+	if err := c.Bind(&cfg); err != nil {
+		t.Fatalf("Bind() error = %v", err)
+	}
+	if cfg.API.Path != "/v1" || cfg.Upstream.Port != 8080 || !cfg.Addr.IsLoopback() {
+		t.Errorf("Bind() = %+v", cfg)
+	}
+}
+
+func TestGetE_SlicesAndMaps(t *testing.T) {
+	tmpFile := createTempFile(t, ".yaml", "hosts:\n  - a.example.com\n  - b.example.com\nports: [80, 443]\nmatrix:\n  - [1, 2]\n  - [3]\n")
+	defer os.Remove(tmpFile)
+
+	c := NewConfig()
+	if err := c.Load(tmpFile); err != nil {
+		t.Fatal(err)
+	}
+	c.Set("TAGS", `web, "a,b", 'c d', "say \"hi\""`)
+	c.Set("IDS", "1,0x10,3")
+	c.Set("BAD_IDS", "1,x,3")
+	c.Set("TIMEOUTS", "1s;2m")
+	c.Set("LIMITS", "read=10, write=20")
+	c.Set("LABELS", `{"team": "core", "tier": 1}`)
+	c.Set("BAD_LIMITS", "read")
+
+	tests := []struct {
+		name string
+		got  func() (any, error)
+		want any
+	}{
+		{"JSON string array", func() (any, error) { return GetE(c, "hosts", []string(nil)) }, []string{"a.example.com", "b.example.com"}},
+		{"JSON number array", func() (any, error) { return GetE(c, "ports", []uint16(nil)) }, []uint16{80, 443}},
+		{"nested JSON arrays", func() (any, error) { return GetE(c, "matrix", [][]int(nil)) }, [][]int{{1, 2}, {3}}},
+		{"quoted elements", func() (any, error) { return GetE(c, "TAGS", []string(nil)) }, []string{"web", "a,b", "c d", `say "hi"`}},
+		{"delimited ints", func() (any, error) { return GetE(c, "IDS", []int64(nil)) }, []int64{1, 16, 3}},
+		{"delimited map", func() (any, error) { return GetE(c, "LIMITS", map[string]int(nil)) }, map[string]int{"read": 10, "write": 20}},
+		{"JSON object", func() (any, error) { return GetE(c, "LABELS", map[string]string(nil)) }, map[string]string{"team": "core", "tier": "1"}},
+		{"raw bytes", func() (any, error) { return GetE(c, "IDS", []byte(nil)) }, []byte("1,0x10,3")},
+		{"map of raw bytes", func() (any, error) { return GetE(c, "LIMITS", map[string][]byte(nil)) }, map[string][]byte{"read": []byte("10"), "write": []byte("20")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.got()
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+
+	if _, err := GetE(c, "BAD_IDS", []int(nil)); err == nil || !strings.Contains(err.Error(), "element 1") {
+		t.Errorf("GetE() error = %v, want an error naming element 1", err)
+	}
+	if _, err := GetE(c, "BAD_LIMITS", map[string]int(nil)); err == nil {
+		t.Error("GetE() expected an error for an entry without =")
+	}
+
+	SetListSeparator(";")
+	defer SetListSeparator("")
+	if got := Get(c, "TIMEOUTS", []time.Duration(nil)); !reflect.DeepEqual(got, []time.Duration{time.Second, 2 * time.Minute}) {
+		t.Errorf("Get() with separator ; = %v", got)
+	}
+	SetListSeparator("")
+
+	var cfg struct {
+		Timeouts []time.Duration `env:"TIMEOUTS" sep:";"`
+		Hosts    []string        `env:"hosts"`
+	}
+	if err := c.Bind(&cfg); err != nil {
+		t.Fatalf("Bind() error = %v", err)
+	}
+	if len(cfg.Timeouts) != 2 || len(cfg.Hosts) != 2 {
+		t.Errorf("Bind() = %+v", cfg)
+	}
+}
@@ -0,0 +1,116 @@
+package goenv
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// Reloader reloads configuration when the process receives SIGHUP, the way
+// nginx and many daemons do. Each signal re-runs every file load of the
+// Config in its original order with Config.Reload and logs the diff. A
+// failed reload keeps the previous values.
+type Reloader struct {
+	Config  *Config     // Config to reload, defaults to Default()
+	Signals []os.Signal // Signals that trigger a reload, defaults to SIGHUP
+	Logger  *log.Logger // Logger for reload results, defaults to log.Default()
+
+	mu     sync.Mutex
+	count  int
+	err    error
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewReloader creates a Reloader for the Default instance
+func NewReloader() *Reloader {
+	return &Reloader{}
+}
+
+// Start starts handling signals until ctx is canceled or Stop is called
+func (r *Reloader) Start(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.done != nil {
+		return errors.New("reloader already started")
+	}
+
+	signals := r.Signals
+	if len(signals) == 0 {
+		signals = []os.Signal{syscall.SIGHUP}
+	}
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, signals...)
+
+	ctx, r.cancel = context.WithCancel(ctx)
+	r.done = make(chan struct{})
+	go func() {
+		defer close(r.done)
+		defer signal.Stop(ch)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ch:
+				r.Reload(ctx)
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop stops handling signals and waits for a reload in progress to finish
+func (r *Reloader) Stop() {
+	r.mu.Lock()
+	cancel, done := r.cancel, r.done
+	r.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// Reload reloads the configuration immediately, as if a signal was received
+func (r *Reloader) Reload(ctx context.Context) (Diff, error) {
+	config := r.Config
+	if config == nil {
+		config = defaultConfig
+	}
+	logger := r.Logger
+	if logger == nil {
+		logger = log.Default()
+	}
+
+	diff, err := config.Reload(ctx)
+	if err != nil {
+		logger.Printf("goenv: reload failed, keeping previous configuration: %v", err)
+	} else {
+		logger.Printf("goenv: configuration reloaded: %s", diff)
+	}
+
+	r.mu.Lock()
+	r.count++
+	r.err = err
+	r.mu.Unlock()
+	return diff, err
+}
+
+// Count returns the number of reloads attempted, including failed ones
+func (r *Reloader) Count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.count
+}
+
+// LastError returns the error of the last reload, or nil if it succeeded
+func (r *Reloader) LastError() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
@@ -0,0 +1,212 @@
+package goenv
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestConfig_Reload(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.env")
+	override := filepath.Join(dir, "override.yaml")
+	writeWatched(t, base, "HOST=localhost\nPORT=8080\nDEBUG=true\n")
+	writeWatched(t, override, "PORT: 9090\n")
+
+	c := NewConfig()
+	if err := c.Load(base); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.LoadWithFormat(FormatYAML, filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Fatal("LoadWithFormat() expected an error for a missing file")
+	}
+	if err := c.Load(override); err != nil {
+		t.Fatal(err)
+	}
+	c.Set("MANUAL", "kept")
+
+	writeWatched(t, base, "HOST=example.com\nPORT=8080\n")
+	diff, err := c.Reload(context.Background())
+	if err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if got := diff.String(); got != "changed: HOST; removed: DEBUG" {
+		t.Errorf("diff = %q", got)
+	}
+	if got := Get(c, "PORT", 0); got != 9090 {
+		t.Errorf("PORT = %v, want 9090 from the later load", got)
+	}
+	if got, _ := c.Lookup("MANUAL"); got != "kept" {
+		t.Errorf("MANUAL = %q, want kept", got)
+	}
+
+	os.Remove(override)
+	if _, err := c.Reload(context.Background()); err == nil {
+		t.Fatal("Reload() expected an error for a removed file")
+	}
+	if got := Get(c, "HOST", ""); got != "example.com" {
+		t.Errorf("HOST after a failed reload = %q, want example.com", got)
+	}
+}
+
+func TestConfig_ReloadKeepsLaterSources(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.env")
+	writeWatched(t, path, "SECRET=file\nDROPPED=file\nHOST=localhost\n")
+
+	c := NewConfig()
+	if err := c.Load(path); err != nil {
+		t.Fatal(err)
+	}
+	vault := MapSource{"SECRET": "vault", "DROPPED": "vault"}
+	if err := c.LoadSources(context.Background(), vault); err != nil {
+		t.Fatal(err)
+	}
+
+	writeWatched(t, path, "SECRET=file\nHOST=example.com\n")
+	diff, err := c.Reload(context.Background())
+	if err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if got := diff.String(); got != "changed: HOST" {
+		t.Errorf("diff = %q, want changed: HOST", got)
+	}
+	for _, key := range []string{"SECRET", "DROPPED"} {
+		if got, _ := c.Lookup(key); got != "vault" {
+			t.Errorf("%s = %q, want vault", key, got)
+		}
+		if got := c.Origin(key); got == path {
+			t.Errorf("Origin(%s) = %q, want the later source", key, got)
+		}
+	}
+}
+
+func TestConfig_ReloadReusesSources(t *testing.T) {
+	var notModified int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(&notModified, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte("REMOTE=value\n"))
+	}))
+	defer server.Close()
+
+	c := NewConfig()
+	for i := 0; i < 2; i++ {
+		if err := c.Load(server.URL + "/app.env"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := c.Reload(context.Background()); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+
+	if got := len(c.history); got != 1 {
+		t.Errorf("history has %d calls, want 1", got)
+	}
+	if got := atomic.LoadInt32(&notModified); got != 2 {
+		t.Errorf("not modified responses = %d, want 2", got)
+	}
+}
+
+func TestConfig_Checkpoint(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.env")
+	fixture := filepath.Join(dir, "fixture.env")
+	writeWatched(t, base, "HOST=localhost\n")
+	writeWatched(t, fixture, "FIXTURE=true\n")
+
+	c := NewConfig()
+	if err := c.Load(base); err != nil {
+		t.Fatal(err)
+	}
+	restore := c.Checkpoint()
+	if err := c.Load(fixture); err != nil {
+		t.Fatal(err)
+	}
+	restore()
+	c.Unset("FIXTURE")
+	os.Remove(fixture)
+
+	diff, err := c.Reload(context.Background())
+	if err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if !diff.Empty() {
+		t.Errorf("diff = %q, want empty", diff)
+	}
+	if _, ok := c.Lookup("FIXTURE"); ok {
+		t.Error("FIXTURE was loaded again after restoring the checkpoint")
+	}
+}
+
+func TestReloader_SIGHUP(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.env")
+	writeWatched(t, path, "LEVEL=info\n")
+
+	c := NewConfig()
+	if err := c.Load(path); err != nil {
+		t.Fatal(err)
+	}
+
+	var logs bytes.Buffer
+	r := &Reloader{Config: c, Logger: log.New(&logs, "", 0)}
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := r.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer r.Stop()
+
+	hangup := func(count int) {
+		t.Helper()
+		process, err := os.FindProcess(os.Getpid())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := process.Signal(syscall.SIGHUP); err != nil {
+			t.Fatal(err)
+		}
+		deadline := time.Now().Add(5 * time.Second)
+		for r.Count() < count {
+			if time.Now().After(deadline) {
+				t.Fatal("timed out waiting for the reload")
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	writeWatched(t, path, "LEVEL=debug\n")
+	hangup(1)
+	if got := Get(c, "LEVEL", ""); got != "debug" {
+		t.Errorf("LEVEL = %q, want debug", got)
+	}
+	if err := r.LastError(); err != nil {
+		t.Errorf("LastError() = %v", err)
+	}
+
+	os.Remove(path)
+	hangup(2)
+	if r.LastError() == nil {
+		t.Error("LastError() = nil after a failed reload")
+	}
+	if got := Get(c, "LEVEL", ""); got != "debug" {
+		t.Errorf("LEVEL after a failed reload = %q, want debug", got)
+	}
+
+	cancel()
+	r.Stop()
+	if out := logs.String(); !strings.Contains(out, "configuration reloaded: changed: LEVEL") || !strings.Contains(out, "reload failed") {
+		t.Errorf("logs = %q", out)
+	}
+}
@@ -0,0 +1,218 @@
+package goenv
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// declaration documents a configuration key for Require and MustGetEnv
+type declaration struct {
+	typ         reflect.Type // Expected type, or nil for any value
+	description string
+	example     string // Location of the key in an example file, e.g. ".env.example:12"
+}
+
+var (
+	declarationsMu sync.RWMutex
+	declarations   = make(map[string]declaration)
+)
+
+// Describe registers a human readable description of key, which is included
+// in the errors of Require and MustGetEnv
+func Describe(key, description string) {
+	declarationsMu.Lock()
+	defer declarationsMu.Unlock()
+
+	decl := declarations[key]
+	decl.description = description
+	declarations[key] = decl
+}
+
+// Declare registers key with its expected type and description. Require
+// reports declared keys whose value cannot be converted to T, and checks every
+// declared key when called without arguments.
+func Declare[T any](key, description string) {
+	declarationsMu.Lock()
+	defer declarationsMu.Unlock()
+
+	decl := declarations[key]
+	decl.typ = reflect.TypeOf((*T)(nil)).Elem()
+	if description != "" {
+		decl.description = description
+	}
+	declarations[key] = decl
+}
+
+// RegisterExample registers the keys of an example key-value file such as
+// .env.example. Errors about a key point at its line in the file, and comment
+// lines directly above a key become its description unless one was set with
+// Describe or Declare.
+func RegisterExample(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	declarationsMu.Lock()
+	defer declarationsMu.Unlock()
+
+	var comments []string
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(text, "#") {
+			comments = append(comments, strings.TrimSpace(strings.TrimPrefix(text, "#")))
+			continue
+		}
+
+		key, _, ok := parseKeyValueLine(text)
+		if ok {
+			decl := declarations[key]
+			decl.example = fmt.Sprintf("%s:%d", path, line)
+			if decl.description == "" && len(comments) > 0 {
+				decl.description = strings.Join(comments, " ")
+			}
+			declarations[key] = decl
+		}
+		comments = nil
+	}
+	return scanner.Err()
+}
+
+// declared returns the declaration of key
+func declared(key string) (declaration, bool) {
+	declarationsMu.RLock()
+	defer declarationsMu.RUnlock()
+	decl, ok := declarations[key]
+	return decl, ok
+}
+
+// declaredKeys returns the sorted keys of all declarations with a type
+func declaredKeys() []string {
+	declarationsMu.RLock()
+	defer declarationsMu.RUnlock()
+
+	var keys []string
+	for key, decl := range declarations {
+		if decl.typ != nil {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// withHint appends the description and example location of key to err
+func withHint(key string, err error) error {
+	decl, ok := declared(key)
+	if !ok {
+		return err
+	}
+
+	var hints []string
+	if decl.description != "" {
+		hints = append(hints, decl.description)
+	}
+	if decl.example != "" {
+		hints = append(hints, "see "+decl.example)
+	}
+	if len(hints) == 0 {
+		return err
+	}
+	return fmt.Errorf("%w (%s)", err, strings.Join(hints, ", "))
+}
+
+// Require checks that every key is set in the Default instance and that
+// declared keys parse as their declared type, see Config.Require
+func Require(keys ...string) error {
+	return defaultConfig.Require(keys...)
+}
+
+// Require checks that every key is set in c and that declared keys parse as
+// their declared type. Without arguments it checks every key registered with
+// Declare. The returned error lists every problem at once, with the
+// description and example file location of each key when registered.
+func (c *Config) Require(keys ...string) error {
+	if len(keys) == 0 {
+		keys = declaredKeys()
+	}
+
+	var errs []error
+	for _, key := range keys {
+		if err := c.require(key); err != nil {
+			errs = append(errs, withHint(key, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// require checks that key is set and parses as its declared type
+func (c *Config) require(key string) error {
+	raw, err := c.lookup(key)
+	if err != nil {
+		return err
+	}
+	if raw == "" {
+		return fmt.Errorf("%s: required but not set", key)
+	}
+
+	if decl, _ := declared(key); decl.typ != nil {
+		if _, err := parseKey(key, raw, decl.typ); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MustGet retrieves a value from c with type conversion, like MustGetEnv
+func MustGet[T any](c *Config, key string) T {
+	val, ok, err := Lookup[T](c, key)
+	if err == nil && !ok {
+		err = fmt.Errorf("%s: required but not set", key)
+	}
+	if err != nil {
+		panic(withHint(key, err))
+	}
+	return val
+}
+
+// MustGetEnv retrieves an environment variable with type conversion and panics
+// when it is unset, empty or cannot be converted. The panic value is an error
+// that includes the registered description of the key.
+func MustGetEnv[T any](key string) T {
+	return MustGet[T](defaultConfig, key)
+}
+
+// MustGetEnvString is a convenience function for required string values
+func MustGetEnvString(key string) string {
+	return MustGetEnv[string](key)
+}
+
+// MustGetEnvInt is a convenience function for required int values
+func MustGetEnvInt(key string) int {
+	return MustGetEnv[int](key)
+}
+
+// MustGetEnvBool is a convenience function for required bool values
+func MustGetEnvBool(key string) bool {
+	return MustGetEnv[bool](key)
+}
+
+// MustGetEnvFloat64 is a convenience function for required float64 values
+func MustGetEnvFloat64(key string) float64 {
+	return MustGetEnv[float64](key)
+}
+
+// MustGetEnvDuration is a convenience function for required time.Duration
+// values
+func MustGetEnvDuration(key string) time.Duration {
+	return MustGetEnv[time.Duration](key)
+}
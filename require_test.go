@@ -0,0 +1,77 @@
+package goenv
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRequire(t *testing.T) {
+	example := filepath.Join(t.TempDir(), ".env.example")
+	writeWatched(t, example, "# Application\n\n# Database password\n# used by the migrator\nREQ_DB_PASSWORD=changeme\nREQ_PORT=8080\n")
+	if err := RegisterExample(example); err != nil {
+		t.Fatalf("RegisterExample() error = %v", err)
+	}
+	Declare[int]("REQ_PORT", "HTTP listen port")
+	Declare[time.Duration]("REQ_TIMEOUT", "")
+	Describe("REQ_TOKEN", "API token")
+
+	c := NewConfig()
+	c.Set("REQ_PORT", "80a")
+	c.Set("REQ_TIMEOUT", "5s")
+	c.Set("REQ_NAME", "api")
+
+	err := c.Require("REQ_NAME", "REQ_DB_PASSWORD", "REQ_PORT", "REQ_TOKEN", "REQ_TIMEOUT")
+	if err == nil {
+		t.Fatal("Require() expected an error")
+	}
+
+	lines := strings.Split(err.Error(), "\n")
+	want := []string{
+		"REQ_DB_PASSWORD: required but not set (Database password used by the migrator, see " + example + ":5)",
+		`REQ_PORT: invalid value "80a" for int: invalid syntax (HTTP listen port, see ` + example + ":6)",
+		"REQ_TOKEN: required but not set (API token)",
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Require() error =\n%s\nwant\n%s", err, strings.Join(want, "\n"))
+	}
+
+	c.Set("REQ_PORT", "8080")
+	if err := c.Require("REQ_NAME", "REQ_PORT", "REQ_TIMEOUT"); err != nil {
+		t.Errorf("Require() error = %v", err)
+	}
+}
+
+func TestMustGetEnv(t *testing.T) {
+	os.Setenv("MUST_PORT", "9090")
+	os.Setenv("MUST_BAD", "soon")
+	defer os.Unsetenv("MUST_PORT")
+	defer os.Unsetenv("MUST_BAD")
+	Describe("MUST_MISSING", "Required for startup")
+
+	if got := MustGetEnvInt("MUST_PORT"); got != 9090 {
+		t.Errorf("MustGetEnvInt() = %v, want 9090", got)
+	}
+
+	tests := []struct {
+		name string
+		fn   func()
+		want string
+	}{
+		{"missing", func() { MustGetEnvString("MUST_MISSING") }, "MUST_MISSING: required but not set (Required for startup)"},
+		{"malformed", func() { MustGetEnvDuration("MUST_BAD") }, `MUST_BAD: invalid value "soon" for time.Duration`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				err, ok := recover().(error)
+				if !ok || !strings.Contains(err.Error(), tt.want) {
+					t.Errorf("panic = %v, want an error containing %q", err, tt.want)
+				}
+			}()
+			tt.fn()
+		})
+	}
+}
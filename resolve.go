@@ -0,0 +1,162 @@
+package goenv
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Resolver turns the reference part of a scheme value into the real value.
+// For TLS_CERT=file:///etc/certs/tls.pem the file resolver receives
+// "///etc/certs/tls.pem".
+type Resolver func(ref string) (string, error)
+
+var (
+	resolversMu sync.RWMutex
+	resolvers   = map[string]Resolver{
+		"file":   resolveFile,
+		"base64": resolveBase64,
+		"env":    nil, // Resolved with the lookup of the loading Config
+	}
+)
+
+// lookupKey is the context key of the lookup that resolves env: references
+type lookupKey struct{}
+
+// withLookup returns a copy of ctx in which sources resolve env: references
+// with lookup
+func withLookup(ctx context.Context, lookup func(string) (string, bool)) context.Context {
+	return context.WithValue(ctx, lookupKey{}, lookup)
+}
+
+// contextLookup returns the lookup set with withLookup, or the process
+// environment for sources loaded outside of a Config
+func contextLookup(ctx context.Context) func(string) (string, bool) {
+	if lookup, ok := ctx.Value(lookupKey{}).(func(string) (string, bool)); ok {
+		return lookup
+	}
+	return os.LookupEnv
+}
+
+// RegisterResolver registers a resolver for values starting with scheme
+// followed by a colon. Registering an existing scheme replaces its resolver
+// and a nil resolver removes it.
+//
+// Resolvers run on every value parsed from a local key-value, JSON or YAML
+// file or reader, and from an HTTPSource only when its Resolve field is set.
+// A value that should be kept literally even though it starts with a
+// registered scheme can be escaped with a leading backslash, e.g.
+// \env:production.
+func RegisterResolver(scheme string, resolver Resolver) {
+	resolversMu.Lock()
+	defer resolversMu.Unlock()
+
+	scheme = strings.ToLower(scheme)
+	if resolver == nil {
+		delete(resolvers, scheme)
+		return
+	}
+	resolvers[scheme] = resolver
+}
+
+// resolveValues resolves every scheme value in values in place, looking up
+// env: references with lookup
+func resolveValues(values map[string]string, lookup func(string) (string, bool)) error {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	// Resolve in a stable order so the reported error is deterministic
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		resolved, err := resolveValue(values[key], lookup)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		values[key] = resolved
+	}
+	return nil
+}
+
+// resolveValue resolves a single value. Values without a registered scheme
+// are returned unchanged.
+func resolveValue(value string, lookup func(string) (string, bool)) (string, error) {
+	if strings.HasPrefix(value, `\`) {
+		if _, _, ok := lookupResolver(value[1:]); ok {
+			return value[1:], nil
+		}
+		return value, nil
+	}
+
+	resolver, ref, ok := lookupResolver(value)
+	if !ok {
+		return value, nil
+	}
+	if resolver == nil {
+		// The built-in env scheme returns the value of another key
+		resolved, _ := lookup(ref)
+		return resolved, nil
+	}
+
+	resolved, err := resolver(ref)
+	if err != nil {
+		scheme, _, _ := strings.Cut(value, ":")
+		return "", fmt.Errorf("resolve %s: %w", scheme, err)
+	}
+	return resolved, nil
+}
+
+// lookupResolver returns the resolver for the scheme of value and the
+// reference following the scheme
+func lookupResolver(value string) (Resolver, string, bool) {
+	scheme, ref, found := strings.Cut(value, ":")
+	if !found || scheme == "" {
+		return nil, "", false
+	}
+
+	resolversMu.RLock()
+	defer resolversMu.RUnlock()
+	resolver, ok := resolvers[strings.ToLower(scheme)]
+	return resolver, ref, ok
+}
+
+// resolveFile reads the content of a file, given as file:///abs/path or
+// file:relative/path
+func resolveFile(ref string) (string, error) {
+	u, err := url.Parse("file:" + ref)
+	if err != nil {
+		return "", err
+	}
+
+	path := u.Opaque
+	if path == "" {
+		path = u.Path
+	}
+	if path == "" {
+		return "", fmt.Errorf("empty file path")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// resolveBase64 decodes standard or URL-safe base64, with or without padding
+func resolveBase64(ref string) (string, error) {
+	for _, encoding := range []*base64.Encoding{
+		base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding,
+	} {
+		if data, err := encoding.DecodeString(ref); err == nil {
+			return string(data), nil
+		}
+	}
+	return "", fmt.Errorf("invalid base64 value")
+}
@@ -0,0 +1,136 @@
+package goenv
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolveValue(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "tls.pem")
+	os.WriteFile(certFile, []byte("-----BEGIN CERTIFICATE-----"), 0o600)
+
+	os.Setenv("OLD_NAME", "legacy value")
+	defer os.Unsetenv("OLD_NAME")
+
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"plain value", "hello", "hello", false},
+		{"unregistered scheme", "https://example.com", "https://example.com", false},
+		{"file URL", "file://" + certFile, "-----BEGIN CERTIFICATE-----", false},
+		{"file path", "file:" + certFile, "-----BEGIN CERTIFICATE-----", false},
+		{"missing file", "file://" + filepath.Join(dir, "missing"), "", true},
+		{"base64", "base64:c2lnbmluZy1rZXk=", "signing-key", false},
+		{"base64 without padding", "base64:c2lnbmluZy1rZXk", "signing-key", false},
+		{"invalid base64", "base64:!!!", "", true},
+		{"env", "env:OLD_NAME", "legacy value", false},
+		{"escaped scheme", `\env:OLD_NAME`, "env:OLD_NAME", false},
+		{"backslash without scheme", `\path\to`, `\path\to`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveValue(tt.value, os.LookupEnv)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveValue() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("resolveValue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisterResolver(t *testing.T) {
+	RegisterResolver("upper", func(ref string) (string, error) {
+		if ref == "" {
+			return "", errors.New("empty reference")
+		}
+		return strings.ToUpper(ref), nil
+	})
+	defer RegisterResolver("upper", nil)
+
+	content := `SHOUT=upper:hello
+LITERAL=\upper:hello
+BROKEN=upper:
+`
+	tmpFile := createTempFile(t, ".env", content)
+	defer os.Remove(tmpFile)
+
+	if err := LoadEnv(tmpFile); err == nil {
+		t.Error("LoadEnv() expected error for failing resolver")
+	}
+
+	os.WriteFile(tmpFile, []byte(strings.Replace(content, "BROKEN=upper:\n", "", 1)), 0o600)
+	defer os.Unsetenv("SHOUT")
+	defer os.Unsetenv("LITERAL")
+	if err := LoadEnv(tmpFile); err != nil {
+		t.Fatalf("LoadEnv() error = %v", err)
+	}
+	if got := os.Getenv("SHOUT"); got != "HELLO" {
+		t.Errorf("SHOUT = %v, want HELLO", got)
+	}
+	if got := os.Getenv("LITERAL"); got != "upper:hello" {
+		t.Errorf("LITERAL = %v, want upper:hello", got)
+	}
+}
+
+func TestResolveValues_AllFormats(t *testing.T) {
+	files := map[string]string{
+		".env":  "signing.key=base64:c2VjcmV0\n",
+		".json": `{"signing": {"key": "base64:c2VjcmV0"}}`,
+		".yaml": "signing:\n  key: base64:c2VjcmV0\n",
+	}
+
+	for suffix, content := range files {
+		t.Run(suffix, func(t *testing.T) {
+			tmpFile := createTempFile(t, suffix, content)
+			defer os.Remove(tmpFile)
+			defer os.Unsetenv("signing.key")
+
+			if err := LoadEnv(tmpFile); err != nil {
+				t.Fatalf("LoadEnv() error = %v", err)
+			}
+			if got := os.Getenv("signing.key"); got != "secret" {
+				t.Errorf("signing.key = %v, want secret", got)
+			}
+		})
+	}
+}
+
+func TestResolveEnv_Config(t *testing.T) {
+	t.Setenv("RESOLVE_PROCESS_ONLY", "process")
+	file := createTempFile(t, ".env", "RESOLVED=env:RESOLVE_PROCESS_ONLY\n")
+	defer os.Remove(file)
+
+	isolated := NewConfig()
+	if err := isolated.Load(file); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got, _ := isolated.Lookup("RESOLVED"); got != "" {
+		t.Errorf("isolated RESOLVED = %q, want empty", got)
+	}
+
+	isolated.Set("RESOLVE_PROCESS_ONLY", "config")
+	if err := isolated.Load(file); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got, _ := isolated.Lookup("RESOLVED"); got != "config" {
+		t.Errorf("isolated RESOLVED after Set = %q, want config", got)
+	}
+
+	fallback := NewConfig(WithEnvFallback())
+	if err := fallback.Load(file); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got, _ := fallback.Lookup("RESOLVED"); got != "process" {
+		t.Errorf("fallback RESOLVED = %q, want process", got)
+	}
+}
@@ -0,0 +1,97 @@
+package goenv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// DefaultSecretFileMaxSize is the default size limit for secret files
+const DefaultSecretFileMaxSize = 64 * 1024
+
+// SecretFileOptions configures how secrets are read from files
+type SecretFileOptions struct {
+	MaxSize        int64 // Maximum file size in bytes, defaults to DefaultSecretFileMaxSize
+	RequirePrivate bool  // Reject files that are accessible by group or others
+}
+
+// EnableSecretFiles enables the _FILE suffix convention used by Docker and
+// Kubernetes secrets: when KEY is unset and KEY_FILE is set, the getters read
+// the value from the file named by KEY_FILE, with a trailing newline removed.
+func EnableSecretFiles(opts SecretFileOptions) {
+	defaultConfig.EnableSecretFiles(opts)
+}
+
+// DisableSecretFiles disables the _FILE suffix convention
+func DisableSecretFiles() {
+	defaultConfig.DisableSecretFiles()
+}
+
+// readSecretFile reads a secret from a regular file, enforcing the size limit
+// and permission checks of opts, and removes a single trailing newline
+func readSecretFile(path string, opts SecretFileOptions) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return "", err
+	}
+	if !info.Mode().IsRegular() {
+		return "", fmt.Errorf("%s is not a regular file", path)
+	}
+	if perm := info.Mode().Perm(); perm&0o002 != 0 {
+		return "", fmt.Errorf("%s is writable by others (mode %04o)", path, perm)
+	} else if opts.RequirePrivate && perm&0o077 != 0 {
+		return "", fmt.Errorf("%s is accessible by group or others (mode %04o)", path, perm)
+	}
+
+	maxSize := opts.MaxSize
+	if maxSize <= 0 {
+		maxSize = DefaultSecretFileMaxSize
+	}
+	data, err := io.ReadAll(io.LimitReader(file, maxSize+1))
+	if err != nil {
+		return "", err
+	}
+	if int64(len(data)) > maxSize {
+		return "", fmt.Errorf("%s exceeds the size limit of %d bytes", path, maxSize)
+	}
+
+	return trimNewline(string(data)), nil
+}
+
+// trimNewline removes a single trailing "\n" or "\r\n"
+func trimNewline(s string) string {
+	if trimmed, ok := strings.CutSuffix(s, "\n"); ok {
+		return strings.TrimSuffix(trimmed, "\r")
+	}
+	return s
+}
+
+// NewCredentialsSource creates a DirSource for the systemd credentials
+// directory named by $CREDENTIALS_DIRECTORY. Each credential file becomes a
+// key, with a trailing newline removed.
+func NewCredentialsSource() *DirSource {
+	return &DirSource{
+		Dir:         os.Getenv("CREDENTIALS_DIRECTORY"),
+		MaxFileSize: DefaultSecretFileMaxSize,
+		TrimNewline: true,
+	}
+}
+
+// LoadCredentials loads the systemd credentials passed to the service with
+// LoadCredential= or SetCredential=
+func LoadCredentials() error {
+	source := NewCredentialsSource()
+	if source.Dir == "" {
+		return errors.New("CREDENTIALS_DIRECTORY is not set")
+	}
+	return LoadSources(context.Background(), source)
+}
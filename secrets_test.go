@@ -0,0 +1,121 @@
+package goenv
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSecretFiles(t *testing.T) {
+	dir := t.TempDir()
+	secret := filepath.Join(dir, "db_password")
+	os.WriteFile(secret, []byte("s3cr3t\n"), 0o600)
+	shared := filepath.Join(dir, "shared")
+	os.WriteFile(shared, []byte("shared\r\n"), 0o644)
+	carriage := filepath.Join(dir, "carriage")
+	os.WriteFile(carriage, []byte("binary\r"), 0o600)
+	large := filepath.Join(dir, "large")
+	os.WriteFile(large, []byte(strings.Repeat("x", 100)), 0o600)
+
+	tests := []struct {
+		name string
+		opts SecretFileOptions
+		env  map[string]string
+		key  string
+		want string
+	}{
+		{
+			name: "reads file and trims newline",
+			env:  map[string]string{"DB_PASSWORD_FILE": secret},
+			key:  "DB_PASSWORD",
+			want: "s3cr3t",
+		},
+		{
+			name: "direct value takes precedence",
+			env:  map[string]string{"DB_PASSWORD": "direct", "DB_PASSWORD_FILE": secret},
+			key:  "DB_PASSWORD",
+			want: "direct",
+		},
+		{
+			name: "trims CRLF",
+			env:  map[string]string{"DB_PASSWORD_FILE": shared},
+			key:  "DB_PASSWORD",
+			want: "shared",
+		},
+		{
+			name: "keeps lone trailing CR",
+			env:  map[string]string{"DB_PASSWORD_FILE": carriage},
+			key:  "DB_PASSWORD",
+			want: "binary\r",
+		},
+		{
+			name: "rejects shared file when private is required",
+			opts: SecretFileOptions{RequirePrivate: true},
+			env:  map[string]string{"DB_PASSWORD_FILE": shared},
+			key:  "DB_PASSWORD",
+			want: "default",
+		},
+		{
+			name: "rejects file over size limit",
+			opts: SecretFileOptions{MaxSize: 10},
+			env:  map[string]string{"DB_PASSWORD_FILE": large},
+			key:  "DB_PASSWORD",
+			want: "default",
+		},
+		{
+			name: "missing file",
+			env:  map[string]string{"DB_PASSWORD_FILE": filepath.Join(dir, "missing")},
+			key:  "DB_PASSWORD",
+			want: "default",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Unsetenv("DB_PASSWORD")
+			os.Unsetenv("DB_PASSWORD_FILE")
+			for key, value := range tt.env {
+				os.Setenv(key, value)
+			}
+
+			EnableSecretFiles(tt.opts)
+			defer DisableSecretFiles()
+
+			if got := GetEnv(tt.key, "default"); got != tt.want {
+				t.Errorf("GetEnv() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("disabled by default", func(t *testing.T) {
+		os.Unsetenv("DB_PASSWORD")
+		os.Setenv("DB_PASSWORD_FILE", secret)
+		if got := GetEnv("DB_PASSWORD", "default"); got != "default" {
+			t.Errorf("GetEnv() = %v, want default", got)
+		}
+	})
+
+	os.Unsetenv("DB_PASSWORD")
+	os.Unsetenv("DB_PASSWORD_FILE")
+}
+
+func TestLoadCredentials(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "API_TOKEN"), []byte("token\n"), 0o400)
+	defer os.Unsetenv("API_TOKEN")
+
+	os.Unsetenv("CREDENTIALS_DIRECTORY")
+	if err := LoadCredentials(); err == nil {
+		t.Error("LoadCredentials() expected error without CREDENTIALS_DIRECTORY")
+	}
+
+	os.Setenv("CREDENTIALS_DIRECTORY", dir)
+	defer os.Unsetenv("CREDENTIALS_DIRECTORY")
+	if err := LoadCredentials(); err != nil {
+		t.Fatalf("LoadCredentials() error = %v", err)
+	}
+	if got := os.Getenv("API_TOKEN"); got != "token" {
+		t.Errorf("API_TOKEN = %q, want token", got)
+	}
+}
@@ -0,0 +1,216 @@
+package goenv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Source is a provider of configuration values. Load returns a flat map of
+// keys to values, using dot notation for nested keys.
+type Source interface {
+	Load(ctx context.Context) (map[string]string, error)
+}
+
+// FileSource loads values from a local file
+type FileSource struct {
+	Path   string
+	Format FileFormat // FormatAuto detects the format from the file extension
+}
+
+// NewFileSource creates a FileSource that auto-detects the file format
+func NewFileSource(path string) *FileSource {
+	return &FileSource{Path: path, Format: FormatAuto}
+}
+
+// Load reads and parses the file
+func (s *FileSource) Load(ctx context.Context) (map[string]string, error) {
+	format := s.Format
+	if format == FormatAuto {
+		format = detectFormat(s.Path)
+	}
+
+	data, err := os.ReadFile(s.Path)
+	if err != nil {
+		return nil, err
+	}
+	return parseLocal(format, data, contextLookup(ctx))
+}
+
+// String returns the file path
+func (s *FileSource) String() string {
+	return s.Path
+}
+
+// ReaderSource loads values from an io.Reader. The reader is consumed on the
+// first call to Load.
+type ReaderSource struct {
+	Reader io.Reader
+	Format FileFormat // FormatAuto is treated as key-value format
+	Name   string     // Optional name used in error messages
+}
+
+// NewReaderSource creates a ReaderSource for the given format
+func NewReaderSource(r io.Reader, format FileFormat) *ReaderSource {
+	return &ReaderSource{Reader: r, Format: format}
+}
+
+// Load reads and parses the content of the reader
+func (s *ReaderSource) Load(ctx context.Context) (map[string]string, error) {
+	data, err := io.ReadAll(s.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return parseLocal(s.Format, data, contextLookup(ctx))
+}
+
+// String returns the name of the source
+func (s *ReaderSource) String() string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return "reader"
+}
+
+// MapSource provides values from an in-memory map
+type MapSource map[string]string
+
+// Load returns a copy of the map
+func (s MapSource) Load(ctx context.Context) (map[string]string, error) {
+	return copyValues(s), nil
+}
+
+// String returns "map", use Named for a more specific name
+func (s MapSource) String() string {
+	return "map"
+}
+
+// namedSource is a Source with a name set by Named
+type namedSource struct {
+	Source
+	name string
+}
+
+// Named returns a Source that loads from source and is reported as name by
+// Config.Origin and in load errors, e.g. Named("defaults", MapSource{...})
+func Named(name string, source Source) Source {
+	return namedSource{Source: source, name: name}
+}
+
+// String returns the name of the source
+func (s namedSource) String() string {
+	return s.name
+}
+
+// LoadSources loads environment variables from the first source that loads
+// successfully, trying them in order like LoadEnv does with files
+func LoadSources(ctx context.Context, sources ...Source) error {
+	return defaultConfig.LoadSources(ctx, sources...)
+}
+
+// loadFirst returns the values and the name of the first source that loads
+// successfully. The returned error reports why each source failed.
+func loadFirst(ctx context.Context, sources []Source) (map[string]string, string, error) {
+	var errs []error
+	for i, source := range sources {
+		if err := ctx.Err(); err != nil {
+			return nil, "", err
+		}
+
+		values, err := source.Load(ctx)
+		if errors.Is(err, ErrDecryption) {
+			// Never fall back to another source when encrypted data was tampered with
+			return nil, "", fmt.Errorf("%s: %w", sourceName(source, i), err)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", sourceName(source, i), err))
+			continue
+		}
+		return values, sourceName(source, i), nil
+	}
+
+	if len(errs) == 0 {
+		return nil, "", errors.New("no sources given")
+	}
+	return nil, "", errors.Join(errs...)
+}
+
+// sourceName returns a human readable name for a source
+func sourceName(source Source, index int) string {
+	if s, ok := source.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return fmt.Sprintf("source #%d", index+1)
+}
+
+// copyValues returns a shallow copy of values
+func copyValues(values map[string]string) map[string]string {
+	out := make(map[string]string, len(values))
+	for key, value := range values {
+		out[key] = value
+	}
+	return out
+}
+
+// decodeValue stores value under key. When decode is set and key has a
+// .json, .yaml or .yml extension, the value is parsed instead and its
+// flattened fields are stored under key without the extension.
+func decodeValue(key string, value []byte, decode bool, out map[string]string) error {
+	if decode {
+		if format := detectFormat(key); format == FormatJSON || format == FormatYAML {
+			values, err := parseFormat(format, value)
+			if err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+
+			base := strings.TrimSuffix(key, filepath.Ext(key))
+			for k, v := range values {
+				if base != "" {
+					k = base + "." + k
+				}
+				out[k] = v
+			}
+			return nil
+		}
+	}
+
+	out[key] = string(value)
+	return nil
+}
+
+// parseLocal parses a local document and resolves its scheme values, looking
+// up env: references with lookup
+func parseLocal(format FileFormat, data []byte, lookup func(string) (string, bool)) (map[string]string, error) {
+	values, err := parseFormat(format, data)
+	if err != nil {
+		return nil, err
+	}
+	if err := resolveValues(values, lookup); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// pathToKey maps a hierarchical name such as /myapp/db/host to a dotted key
+// relative to prefix, e.g. db.host for prefix /myapp. Names outside of the
+// prefix directory, such as /myapp2/db, map to an empty key.
+func pathToKey(prefix, name string) string {
+	rest, ok := strings.CutPrefix(name, dirPrefix(prefix))
+	if !ok {
+		return ""
+	}
+	return strings.ReplaceAll(strings.Trim(rest, "/"), "/", ".")
+}
+
+// dirPrefix adds a trailing "/" to a non-empty key prefix, so that it does
+// not match sibling keys that merely start with the same characters
+func dirPrefix(prefix string) string {
+	if prefix == "" || strings.HasSuffix(prefix, "/") {
+		return prefix
+	}
+	return prefix + "/"
+}
@@ -0,0 +1,89 @@
+package goenv
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+type failingSource struct{}
+
+func (failingSource) Load(ctx context.Context) (map[string]string, error) {
+	return nil, errors.New("backend unavailable")
+}
+
+func TestSources(t *testing.T) {
+	jsonFile := createTempFile(t, ".json", `{"source": {"name": "file"}}`)
+	defer os.Remove(jsonFile)
+
+	tests := []struct {
+		name   string
+		source Source
+		key    string
+		want   string
+	}{
+		{
+			name:   "file source",
+			source: NewFileSource(jsonFile),
+			key:    "source.name",
+			want:   "file",
+		},
+		{
+			name:   "reader source",
+			source: NewReaderSource(strings.NewReader("source:\n  name: reader\n"), FormatYAML),
+			key:    "source.name",
+			want:   "reader",
+		},
+		{
+			name:   "map source",
+			source: MapSource{"SOURCE_NAME": "map"},
+			key:    "SOURCE_NAME",
+			want:   "map",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Unsetenv(tt.key)
+			defer os.Unsetenv(tt.key)
+
+			if err := LoadSources(context.Background(), tt.source); err != nil {
+				t.Fatalf("LoadSources() error = %v", err)
+			}
+			if got := os.Getenv(tt.key); got != tt.want {
+				t.Errorf("Environment variable %s = %v, want %v", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadSources_Precedence(t *testing.T) {
+	defer os.Unsetenv("SOURCE_ORDER")
+
+	err := LoadSources(context.Background(),
+		failingSource{},
+		MapSource{"SOURCE_ORDER": "second"},
+		MapSource{"SOURCE_ORDER": "third"},
+	)
+	if err != nil {
+		t.Fatalf("LoadSources() error = %v", err)
+	}
+	if got := os.Getenv("SOURCE_ORDER"); got != "second" {
+		t.Errorf("SOURCE_ORDER = %v, want second", got)
+	}
+}
+
+func TestLoadSources_Errors(t *testing.T) {
+	err := LoadSources(context.Background(), failingSource{}, NewFileSource("non_existent.env"))
+	if err == nil {
+		t.Fatal("LoadSources() expected error")
+	}
+
+	for _, want := range []string{"source #1: backend unavailable", "non_existent.env"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("LoadSources() error = %v, want it to contain %q", err, want)
+		}
+	}
+}
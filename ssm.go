@@ -0,0 +1,194 @@
+package goenv
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SSMSource loads every parameter under an AWS Systems Manager Parameter
+// Store path. The hierarchy is walked recursively and SecureString values are
+// decrypted. With path /myapp/prod/, the parameter /myapp/prod/db/password is
+// loaded as db.password.
+type SSMSource struct {
+	Path            string // Parameter path, e.g. "/myapp/prod/"
+	Region          string // AWS region, defaults to $AWS_REGION or $AWS_DEFAULT_REGION
+	Endpoint        string // Custom endpoint, defaults to https://ssm.<region>.amazonaws.com
+	AccessKeyID     string // Defaults to $AWS_ACCESS_KEY_ID
+	SecretAccessKey string // Defaults to $AWS_SECRET_ACCESS_KEY
+	SessionToken    string // Defaults to $AWS_SESSION_TOKEN
+	Timeout         time.Duration
+	Client          *http.Client
+}
+
+// NewSSMSource creates an SSMSource for a parameter path, reading the region
+// and credentials from the standard AWS environment variables
+func NewSSMSource(path string) *SSMSource {
+	region := os.Getenv("AWS_REGION")
+	if region == "" {
+		region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+
+	return &SSMSource{
+		Path:            path,
+		Region:          region,
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+}
+
+// Load fetches all parameters under the path, following pagination
+func (s *SSMSource) Load(ctx context.Context) (map[string]string, error) {
+	if s.Region == "" {
+		return nil, errors.New("ssm region is not set")
+	}
+	if s.AccessKeyID == "" || s.SecretAccessKey == "" {
+		return nil, errors.New("aws credentials are not set")
+	}
+
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = defaultHTTPTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	input := struct {
+		Path           string
+		Recursive      bool
+		WithDecryption bool
+		NextToken      string `json:",omitempty"`
+	}{Path: s.Path, Recursive: true, WithDecryption: true}
+
+	values := make(map[string]string)
+	for {
+		var resp struct {
+			Parameters []struct {
+				Name  string
+				Value string
+			}
+			NextToken string
+		}
+		if err := s.call(ctx, "GetParametersByPath", input, &resp); err != nil {
+			return nil, fmt.Errorf("ssm get parameters %s: %w", s.Path, err)
+		}
+
+		for _, param := range resp.Parameters {
+			key := pathToKey(s.Path, param.Name)
+			if key != "" {
+				values[key] = param.Value
+			}
+		}
+
+		if resp.NextToken == "" {
+			return values, nil
+		}
+		input.NextToken = resp.NextToken
+	}
+}
+
+// call invokes an SSM API action with a signed JSON request
+func (s *SSMSource) call(ctx context.Context, action string, input, out interface{}) error {
+	body, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+
+	endpoint := s.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://ssm.%s.amazonaws.com", s.Region)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(endpoint, "/")+"/", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "AmazonSSM."+action)
+	if s.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.SessionToken)
+	}
+	signV4(req, body, s.Region, "ssm", s.AccessKeyID, s.SecretAccessKey, time.Now().UTC())
+
+	return doJSON(s.Client, req, out)
+}
+
+// String returns the location of the parameter path
+func (s *SSMSource) String() string {
+	return "ssm:" + s.Path
+}
+
+// signV4 signs req with AWS Signature Version 4. Every header already set on
+// req is signed, together with Host and X-Amz-Date.
+func signV4(req *http.Request, body []byte, region, service, accessKeyID, secretAccessKey string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for key, values := range req.Header {
+		headers[strings.ToLower(key)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		hashHex(body),
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hashHex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+secretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKeyID, scope, signedHeaders, signature))
+}
+
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,90 @@
+package goenv
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSSMSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Amz-Target") != "AmazonSSM.GetParametersByPath" ||
+			!strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") ||
+			r.Header.Get("X-Amz-Security-Token") != "session" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"__type": "InvalidSignatureException"}`))
+			return
+		}
+
+		var input struct {
+			Path           string
+			Recursive      bool
+			WithDecryption bool
+			NextToken      string
+		}
+		json.NewDecoder(r.Body).Decode(&input)
+		if input.Path != "/myapp/prod/" || !input.Recursive || !input.WithDecryption {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		switch input.NextToken {
+		case "":
+			w.Write([]byte(`{"Parameters": [{"Name": "/myapp/prod/db/host", "Value": "db.internal"}], "NextToken": "page2"}`))
+		case "page2":
+			w.Write([]byte(`{"Parameters": [{"Name": "/myapp/prod/db/password", "Type": "SecureString", "Value": "s3cr3t"}, {"Name": "/myapp/prod/port", "Value": "8080"}]}`))
+		}
+	}))
+	defer server.Close()
+
+	source := &SSMSource{
+		Path:            "/myapp/prod/",
+		Region:          "us-east-1",
+		Endpoint:        server.URL,
+		AccessKeyID:     "AKID",
+		SecretAccessKey: "secret",
+		SessionToken:    "session",
+	}
+
+	keys := []string{"db.host", "db.password", "port"}
+	defer func() {
+		for _, key := range keys {
+			os.Unsetenv(key)
+		}
+	}()
+
+	if err := LoadSources(context.Background(), source); err != nil {
+		t.Fatalf("LoadSources() error = %v", err)
+	}
+
+	if got := GetEnvString("db.host", ""); got != "db.internal" {
+		t.Errorf("db.host = %v, want db.internal", got)
+	}
+	if got := GetEnvString("db.password", ""); got != "s3cr3t" {
+		t.Errorf("db.password = %v, want s3cr3t", got)
+	}
+	if got := GetEnvInt("port", 0); got != 8080 {
+		t.Errorf("port = %v, want 8080", got)
+	}
+}
+
+func TestSignV4(t *testing.T) {
+	// Example request from the AWS Signature Version 4 documentation
+	req, _ := http.NewRequest(http.MethodGet, "https://iam.amazonaws.com/?Action=ListUsers&Version=2010-05-08", nil)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+	signV4(req, nil, "us-east-1", "iam", "AKIDEXAMPLE", "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", now)
+
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, " +
+		"SignedHeaders=content-type;host;x-amz-date, " +
+		"Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("Authorization = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,236 @@
+package goenv
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Validator is implemented by bound structs that check their own values.
+// Bind calls Validate after all fields of the struct were set without errors.
+type Validator interface {
+	Validate() error
+}
+
+// rule is a single rule of a validate tag
+type rule struct {
+	name string
+	arg  string
+}
+
+// parseRules parses a validate tag such as "min=1,max=65535". Since patterns
+// may contain commas, a regexp rule takes the rest of the tag.
+func parseRules(tag string) []rule {
+	var rules []rule
+	for tag != "" {
+		var part string
+		if strings.HasPrefix(tag, "regexp=") {
+			part, tag = tag, ""
+		} else {
+			part, tag, _ = strings.Cut(tag, ",")
+		}
+		name, arg, _ := strings.Cut(strings.TrimSpace(part), "=")
+		if name != "" {
+			rules = append(rules, rule{name: name, arg: arg})
+		}
+	}
+	return rules
+}
+
+// requiredIf reports whether the required_if rule of a field applies. The
+// condition key is resolved relative to prefix, like the field itself.
+func (c *Config) requiredIf(rules []rule, prefix string) (string, bool) {
+	for _, r := range rules {
+		if r.name != "required_if" {
+			continue
+		}
+		key, want, _ := strings.Cut(r.arg, " ")
+		got, _ := c.lookup(prefix + key)
+		if sameValue(got, want) {
+			return fmt.Sprintf("%s is %s", prefix+key, want), true
+		}
+	}
+	return "", false
+}
+
+// sameValue compares two raw values, treating equal booleans such as "1" and
+// "true" as the same
+func sameValue(a, b string) bool {
+	if x, err := strconv.ParseBool(a); err == nil {
+		if y, err := strconv.ParseBool(b); err == nil {
+			return x == y
+		}
+	}
+	return a == b
+}
+
+// validateField checks the value of a field against its rules. When the key
+// was not set, only nonempty is checked.
+func validateField(value reflect.Value, rules []rule, key, origin string, set bool) error {
+	var errs []error
+	for _, r := range rules {
+		if !set && r.name != "nonempty" {
+			continue
+		}
+		if err := checkRule(value, r); err != nil {
+			if origin != "" {
+				errs = append(errs, fmt.Errorf("%s (from %s): %w", key, origin, err))
+			} else {
+				errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// checkRule checks a single rule
+func checkRule(value reflect.Value, r rule) error {
+	str := fmt.Sprint(value.Interface())
+
+	switch r.name {
+	case "required_if":
+		return nil
+	case "min", "max":
+		return checkBound(value, r)
+	case "oneof":
+		options := strings.Fields(r.arg)
+		for _, option := range options {
+			if str == option {
+				return nil
+			}
+		}
+		return fmt.Errorf("must be one of %s", strings.Join(options, ", "))
+	case "regexp":
+		pattern, err := regexp.Compile(r.arg)
+		if err != nil {
+			return fmt.Errorf("invalid regexp rule: %v", err)
+		}
+		if !pattern.MatchString(str) {
+			return fmt.Errorf("must match %s", r.arg)
+		}
+	case "url":
+		if u, err := url.Parse(str); err != nil || u.Scheme == "" || u.Host == "" {
+			return errors.New("must be an absolute URL")
+		}
+	case "hostname_port":
+		_, port, err := net.SplitHostPort(str)
+		if err != nil {
+			return errors.New("must be in host:port form")
+		}
+		if n, err := strconv.ParseUint(port, 10, 16); err != nil || n == 0 {
+			return fmt.Errorf("invalid port %q", port)
+		}
+	case "nonempty":
+		switch value.Kind() {
+		case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+			if value.Len() == 0 {
+				return errors.New("must not be empty")
+			}
+		default:
+			if value.IsZero() {
+				return errors.New("must not be empty")
+			}
+		}
+	default:
+		return fmt.Errorf("unknown validation rule %q", r.name)
+	}
+	return nil
+}
+
+// checkRuleTag reports unknown rules and invalid rule arguments of a field of
+// type typ. It runs whether or not the field is set, so that a typo in a tag
+// fails Bind right away rather than once the key is set.
+func checkRuleTag(rules []rule, typ reflect.Type) error {
+	var errs []error
+	for _, r := range rules {
+		var err error
+		switch r.name {
+		case "url", "hostname_port", "nonempty":
+		case "min", "max":
+			_, _, err = compareBound(reflect.New(typ).Elem(), r)
+		case "oneof":
+			if len(strings.Fields(r.arg)) == 0 {
+				err = errors.New("oneof rule without options")
+			}
+		case "regexp":
+			if _, compileErr := regexp.Compile(r.arg); compileErr != nil {
+				err = fmt.Errorf("invalid regexp rule: %v", compileErr)
+			}
+		case "required_if":
+			if key, _, ok := strings.Cut(r.arg, " "); !ok || key == "" {
+				err = fmt.Errorf("invalid required_if rule %q, want KEY value", r.arg)
+			}
+		default:
+			err = fmt.Errorf("unknown validation rule %q", r.name)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// checkBound checks a min or max rule. Numbers are compared by value, and
+// strings, slices and maps by length.
+func checkBound(value reflect.Value, r rule) error {
+	order, subject, err := compareBound(value, r)
+	if err != nil {
+		return err
+	}
+
+	if r.name == "min" && order < 0 {
+		return fmt.Errorf("%s be at least %s", subject, r.arg)
+	} else if r.name == "max" && order > 0 {
+		return fmt.Errorf("%s be at most %s", subject, r.arg)
+	}
+	return nil
+}
+
+// compareBound compares value to the argument of a min or max rule and
+// returns the start of the message for a violated bound
+func compareBound(value reflect.Value, r rule) (int, string, error) {
+	var order int
+	var err error
+	subject := "must"
+	switch kind := value.Kind(); {
+	case value.Type() == reflect.TypeOf(time.Duration(0)):
+		var bound time.Duration
+		if bound, err = time.ParseDuration(r.arg); err == nil {
+			order = cmp.Compare(value.Int(), int64(bound))
+		}
+	case kind >= reflect.Int && kind <= reflect.Int64:
+		var bound int64
+		if bound, err = strconv.ParseInt(r.arg, 10, 64); err == nil {
+			order = cmp.Compare(value.Int(), bound)
+		}
+	case kind >= reflect.Uint && kind <= reflect.Uintptr:
+		var bound uint64
+		if bound, err = strconv.ParseUint(r.arg, 10, 64); err == nil {
+			order = cmp.Compare(value.Uint(), bound)
+		}
+	case kind == reflect.Float32 || kind == reflect.Float64:
+		var bound float64
+		if bound, err = strconv.ParseFloat(r.arg, 64); err == nil {
+			order = cmp.Compare(value.Float(), bound)
+		}
+	case kind == reflect.String || kind == reflect.Slice || kind == reflect.Map || kind == reflect.Array:
+		var bound int
+		if bound, err = strconv.Atoi(r.arg); err == nil {
+			order = cmp.Compare(value.Len(), bound)
+		}
+		subject = "length must"
+	default:
+		return 0, "", fmt.Errorf("%s rule is not supported for %s", r.name, value.Type())
+	}
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid %s rule %q", r.name, r.arg)
+	}
+	return order, subject, nil
+}
@@ -0,0 +1,210 @@
+package goenv
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+type TLSConfig struct {
+	Enabled bool   `env:"TLS_ENABLED"`
+	Cert    string `env:"TLS_CERT" validate:"required_if=TLS_ENABLED true"`
+	Key     string `env:"TLS_KEY" validate:"required_if=TLS_ENABLED true"`
+}
+
+type limits struct {
+	Min int `env:"min"`
+	Max int `env:"max"`
+}
+
+func (l *limits) Validate() error {
+	if l.Min > l.Max {
+		return errors.New("min must not exceed max")
+	}
+	return nil
+}
+
+func TestBind_Validate(t *testing.T) {
+	tmpFile := createTempFile(t, ".yaml", "port: 70000\nlevel: verbose\nlimits:\n  min: 10\n  max: 5\n")
+	defer os.Remove(tmpFile)
+
+	c := NewConfig()
+	if err := c.Load(tmpFile); err != nil {
+		t.Fatal(err)
+	}
+	c.Set("NAME", "")
+	c.Set("ENDPOINT", "/relative")
+	c.Set("ADDR", "localhost")
+	c.Set("TAG", "v1,x")
+	c.Set("TLS_ENABLED", "1")
+	c.Set("TLS_KEY", "key.pem")
+
+	var cfg struct {
+		Port     int           `env:"port" validate:"min=1,max=65535"`
+		Level    string        `env:"level" validate:"oneof=debug info warn error"`
+		Name     string        `env:"NAME" validate:"nonempty"`
+		Endpoint string        `env:"ENDPOINT" validate:"url"`
+		Addr     string        `env:"ADDR" validate:"hostname_port"`
+		Tag      string        `env:"TAG" validate:"min=1,regexp=^v[0-9]+(,[0-9]+)?$"`
+		Timeout  time.Duration `env:"TIMEOUT" default:"500ms" validate:"min=1s"`
+		Optional int           `env:"OPTIONAL" validate:"min=1"`
+		TLSConfig
+		Limits limits `env:"limits"`
+	}
+
+	err := c.Bind(&cfg)
+	if err == nil {
+		t.Fatal("Bind() expected validation errors")
+	}
+
+	for _, want := range []string{
+		"port (from " + tmpFile + "): must be at most 65535",
+		"level (from " + tmpFile + "): must be one of debug, info, warn, error",
+		"NAME: must not be empty",
+		"ENDPOINT: must be an absolute URL",
+		"ADDR: must be in host:port form",
+		"TAG: must match ^v[0-9]+(,[0-9]+)?$",
+		"TIMEOUT (from default): must be at least 1s",
+		"TLS_CERT: required when TLS_ENABLED is true but not set",
+		"limits: min must not exceed max",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not contain %q", err, want)
+		}
+	}
+	for _, unwanted := range []string{"OPTIONAL", "TLS_KEY"} {
+		if strings.Contains(err.Error(), unwanted) {
+			t.Errorf("error %q unexpectedly mentions %s", err, unwanted)
+		}
+	}
+}
+
+func TestBind_ValidatePasses(t *testing.T) {
+	c := NewConfig()
+	c.Set("PORT", "8080")
+	c.Set("ADDR", "db.internal:5432")
+	c.Set("ENDPOINT", "https://api.example.com/v1")
+
+	var cfg struct {
+		Port     int    `env:"PORT" validate:"min=1,max=65535"`
+		Addr     string `env:"ADDR" validate:"hostname_port,nonempty"`
+		Endpoint string `env:"ENDPOINT" validate:"url"`
+		Level    string `env:"LEVEL" default:"info" validate:"oneof=debug info"`
+		TLSConfig
+	}
+	if err := c.Bind(&cfg); err != nil {
+		t.Errorf("Bind() error = %v", err)
+	}
+}
+
+func TestConfig_Origin(t *testing.T) {
+	tmpFile := createTempFile(t, ".env", "FROM_FILE=1\n")
+	defer os.Remove(tmpFile)
+	os.Setenv("ORIGIN_ENV_ONLY", "1")
+	defer os.Unsetenv("ORIGIN_ENV_ONLY")
+
+	c := NewConfig(WithEnvFallback())
+	if err := c.Load(tmpFile); err != nil {
+		t.Fatal(err)
+	}
+	c.Set("MANUAL", "1")
+
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"FROM_FILE", tmpFile},
+		{"ORIGIN_ENV_ONLY", "environment"},
+		{"MANUAL", ""},
+		{"MISSING", ""},
+	}
+	for _, tt := range tests {
+		if got := c.Origin(tt.key); got != tt.want {
+			t.Errorf("Origin(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+
+	c.Set("FROM_FILE", "2")
+	if got := c.Origin("FROM_FILE"); got != "" {
+		t.Errorf("Origin() after Set() = %q, want empty", got)
+	}
+}
+
+func TestOrigin_Unsetenv(t *testing.T) {
+	tmpFile := createTempFile(t, ".env", "ORIGIN_UNSET=1\n")
+	defer os.Remove(tmpFile)
+	defer os.Unsetenv("ORIGIN_UNSET")
+
+	if err := LoadEnv(tmpFile); err != nil {
+		t.Fatal(err)
+	}
+	os.Unsetenv("ORIGIN_UNSET")
+	if got := Default().Origin("ORIGIN_UNSET"); got != "" {
+		t.Errorf("Origin() after os.Unsetenv() = %q, want empty", got)
+	}
+
+	os.Setenv("ORIGIN_UNSET", "2")
+	if got := Default().Origin("ORIGIN_UNSET"); got != "environment" {
+		t.Errorf("Origin() after os.Setenv() = %q, want environment", got)
+	}
+}
+
+func TestBind_InvalidRules(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  any
+		want string
+	}{
+		{"unknown rule", &struct {
+			Name string `env:"RULES_UNSET" validate:"mn=3"`
+		}{}, `unknown validation rule "mn"`},
+		{"invalid bound", &struct {
+			Port int `env:"RULES_UNSET" validate:"max=lots"`
+		}{}, `invalid max rule "lots"`},
+		{"duration bound", &struct {
+			Timeout time.Duration `env:"RULES_UNSET" validate:"min=5"`
+		}{}, `invalid min rule "5"`},
+		{"unsupported bound", &struct {
+			Debug bool `env:"RULES_UNSET" validate:"min=1"`
+		}{}, "min rule is not supported for bool"},
+		{"invalid regexp", &struct {
+			Tag string `env:"RULES_UNSET" validate:"regexp=^v[0-9"`
+		}{}, "invalid regexp rule"},
+		{"invalid required_if", &struct {
+			Cert string `env:"RULES_UNSET" validate:"required_if=TLS_ENABLED"`
+		}{}, "invalid required_if rule"},
+	}
+
+	c := NewConfig()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := c.Bind(tt.cfg); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Bind() error = %v, want %q for an unset key", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrigin_Sources(t *testing.T) {
+	c := NewConfig()
+	ctx := context.Background()
+	if err := c.LoadSources(ctx, MapSource{"FROM_MAP": "1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.LoadSources(ctx, Named("defaults", MapSource{"PORT": "0"})); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Origin("FROM_MAP"); got != "map" {
+		t.Errorf("Origin(FROM_MAP) = %q, want map", got)
+	}
+
+	var cfg struct {
+		Port int `env:"PORT" validate:"min=1"`
+	}
+	if err := c.Bind(&cfg); err == nil || !strings.Contains(err.Error(), "PORT (from defaults)") {
+		t.Errorf("Bind() error = %v, want the name of the source", err)
+	}
+}
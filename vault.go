@@ -0,0 +1,169 @@
+package goenv
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// VaultSource loads the fields of a HashiCorp Vault KV v2 secret. It
+// authenticates with a token or, when no token is set, with AppRole.
+type VaultSource struct {
+	Address      string // Vault address, defaults to $VAULT_ADDR
+	Mount        string // KV v2 mount path, defaults to "secret"
+	Path         string // Secret path within the mount
+	Prefix       string // Optional prefix for the loaded keys, e.g. "db" gives "db.password"
+	Token        string // Vault token, defaults to $VAULT_TOKEN
+	RoleID       string // AppRole role ID, used when no token is available
+	SecretID     string // AppRole secret ID
+	AppRoleMount string // AppRole auth mount path, defaults to "approle"
+	Namespace    string // Vault Enterprise namespace
+	Timeout      time.Duration
+	Client       *http.Client
+
+	mu    sync.Mutex
+	token string // token obtained through AppRole login
+}
+
+// NewVaultSource creates a VaultSource for a secret path on the default KV v2
+// mount, reading the address and token from $VAULT_ADDR and $VAULT_TOKEN
+func NewVaultSource(path string) *VaultSource {
+	return &VaultSource{
+		Address: os.Getenv("VAULT_ADDR"),
+		Mount:   "secret",
+		Path:    path,
+		Token:   os.Getenv("VAULT_TOKEN"),
+	}
+}
+
+// Load reads the latest version of the secret and returns its fields
+func (s *VaultSource) Load(ctx context.Context) (map[string]string, error) {
+	if s.Address == "" {
+		return nil, errors.New("vault address is not set")
+	}
+
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = defaultHTTPTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := s.read(ctx)
+	var statusErr *statusError
+	if errors.As(err, &statusErr) && statusErr.Code == http.StatusForbidden && s.token != "" {
+		// The AppRole token may have expired, log in again once
+		s.token = ""
+		data, err = s.read(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string)
+	flatten(s.Prefix, data, values)
+	return values, nil
+}
+
+// read fetches the secret data, logging in with AppRole when needed
+func (s *VaultSource) read(ctx context.Context) (map[string]interface{}, error) {
+	token, err := s.authToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	mount := s.Mount
+	if mount == "" {
+		mount = "secret"
+	}
+	url := fmt.Sprintf("%s/v1/%s/data/%s", strings.TrimRight(s.Address, "/"),
+		strings.Trim(mount, "/"), strings.TrimLeft(s.Path, "/"))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	s.setHeaders(req)
+	req.Header.Set("X-Vault-Token", token)
+
+	var resp struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+	if err := doJSON(s.Client, req, &resp); err != nil {
+		return nil, fmt.Errorf("vault read %s: %w", s.Path, err)
+	}
+	return resp.Data.Data, nil
+}
+
+// authToken returns the configured token or logs in with AppRole
+func (s *VaultSource) authToken(ctx context.Context) (string, error) {
+	if s.Token != "" {
+		return s.Token, nil
+	}
+	if s.token != "" {
+		return s.token, nil
+	}
+	if s.RoleID == "" {
+		return "", errors.New("vault token or AppRole role ID is required")
+	}
+
+	mount := s.AppRoleMount
+	if mount == "" {
+		mount = "approle"
+	}
+	url := fmt.Sprintf("%s/v1/auth/%s/login", strings.TrimRight(s.Address, "/"), strings.Trim(mount, "/"))
+
+	body, err := json.Marshal(map[string]string{"role_id": s.RoleID, "secret_id": s.SecretID})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	s.setHeaders(req)
+	req.Header.Set("Content-Type", "application/json")
+
+	var resp struct {
+		Auth struct {
+			ClientToken string `json:"client_token"`
+		} `json:"auth"`
+	}
+	if err := doJSON(s.Client, req, &resp); err != nil {
+		return "", fmt.Errorf("vault approle login: %w", err)
+	}
+	if resp.Auth.ClientToken == "" {
+		return "", errors.New("vault approle login: no client token in response")
+	}
+
+	s.token = resp.Auth.ClientToken
+	return s.token, nil
+}
+
+// setHeaders sets headers shared by every Vault request
+func (s *VaultSource) setHeaders(req *http.Request) {
+	if s.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", s.Namespace)
+	}
+}
+
+// String returns the location of the secret
+func (s *VaultSource) String() string {
+	mount := s.Mount
+	if mount == "" {
+		mount = "secret"
+	}
+	return fmt.Sprintf("vault:%s/%s", strings.Trim(mount, "/"), strings.TrimLeft(s.Path, "/"))
+}
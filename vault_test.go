@@ -0,0 +1,97 @@
+package goenv
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newVaultServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/auth/approle/login":
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			if body["role_id"] != "role" || body["secret_id"] != "secret" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(`{"auth": {"client_token": "approle-token"}}`))
+		case "/v1/secret/data/myapp/db":
+			token := r.Header.Get("X-Vault-Token")
+			if token != "root-token" && token != "approle-token" {
+				w.WriteHeader(http.StatusForbidden)
+				w.Write([]byte(`{"errors": ["permission denied"]}`))
+				return
+			}
+			w.Write([]byte(`{"data": {"data": {"password": "s3cr3t", "port": 5432, "tls": {"enabled": true}}, "metadata": {"version": 3}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestVaultSource(t *testing.T) {
+	server := newVaultServer(t)
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		source  *VaultSource
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name:   "token auth",
+			source: &VaultSource{Address: server.URL, Path: "myapp/db", Token: "root-token"},
+			want:   map[string]string{"password": "s3cr3t", "port": "5432", "tls.enabled": "true"},
+		},
+		{
+			name:   "approle auth with prefix",
+			source: &VaultSource{Address: server.URL, Path: "myapp/db", RoleID: "role", SecretID: "secret", Prefix: "db"},
+			want:   map[string]string{"db.password": "s3cr3t", "db.port": "5432", "db.tls.enabled": "true"},
+		},
+		{
+			name:    "invalid token",
+			source:  &VaultSource{Address: server.URL, Path: "myapp/db", Token: "wrong"},
+			wantErr: true,
+		},
+		{
+			name:    "missing credentials",
+			source:  &VaultSource{Address: server.URL, Path: "myapp/db"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := tt.source.Load(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for key, want := range tt.want {
+				if got := values[key]; got != want {
+					t.Errorf("Load()[%s] = %v, want %v", key, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestVaultSource_ReLogin(t *testing.T) {
+	server := newVaultServer(t)
+	defer server.Close()
+
+	source := &VaultSource{Address: server.URL, Path: "myapp/db", RoleID: "role", SecretID: "secret"}
+	source.token = "expired-token"
+
+	values, err := source.Load(context.Background())
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got := values["password"]; got != "s3cr3t" {
+		t.Errorf("password = %v, want s3cr3t", got)
+	}
+}
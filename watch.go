@@ -0,0 +1,195 @@
+package goenv
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	defaultWatchInterval = time.Second
+	defaultWatchDebounce = 100 * time.Millisecond
+)
+
+// Watcher reloads configuration files when they change. It polls the files
+// for changes to their size or modification time, waits until they have been
+// stable for Debounce, then loads them like LoadEnv and atomically applies the
+// new values. Keys that disappear from the files are removed.
+//
+// When a reload fails, for example because a file is half written or no longer
+// parses, the last good values are kept and the error is reported by Err until
+// the next successful reload.
+type Watcher struct {
+	Files    []string
+	Format   FileFormat
+	Config   *Config       // Config to update, defaults to Default()
+	Interval time.Duration // Polling interval, defaults to 1s
+	Debounce time.Duration // Quiet period before reloading, defaults to 100ms
+
+	mu          sync.Mutex
+	values      map[string]string
+	err         error
+	subscribers []func(Diff)
+	cancel      context.CancelFunc
+	done        chan struct{}
+}
+
+// NewWatcher creates a Watcher for files loaded with format auto-detection
+func NewWatcher(files ...string) *Watcher {
+	return &Watcher{Files: files}
+}
+
+// Subscribe registers fn to be called with the diff of every reload that
+// changes at least one key. Subscribers are called in the watcher goroutine,
+// in the order they were registered.
+func (w *Watcher) Subscribe(fn func(Diff)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.subscribers = append(w.subscribers, fn)
+}
+
+// Start loads the files and starts watching them until ctx is canceled or Stop
+// is called. It returns an error if the initial load fails. A stopped Watcher
+// can be started again.
+func (w *Watcher) Start(ctx context.Context) error {
+	w.mu.Lock()
+	if w.done != nil {
+		w.mu.Unlock()
+		return errors.New("watcher already started")
+	}
+	ctx, w.cancel = context.WithCancel(ctx)
+	done := make(chan struct{})
+	w.done = done
+	w.mu.Unlock()
+
+	state := w.stat()
+	if err := w.reload(ctx); err != nil {
+		w.mu.Lock()
+		w.cancel()
+		w.cancel, w.done = nil, nil
+		w.mu.Unlock()
+		return err
+	}
+
+	go w.run(ctx, state, done)
+	return nil
+}
+
+// Stop stops watching and waits for a reload in progress to finish
+func (w *Watcher) Stop() {
+	w.mu.Lock()
+	cancel, done := w.cancel, w.done
+	w.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// Err returns the error of the last reload, or nil if it succeeded
+func (w *Watcher) Err() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+// run polls the files until ctx is canceled, then marks the watcher as
+// stopped and closes done
+func (w *Watcher) run(ctx context.Context, state []fileState, done chan struct{}) {
+	defer func() {
+		w.mu.Lock()
+		w.cancel()
+		w.cancel, w.done = nil, nil
+		w.mu.Unlock()
+		close(done)
+	}()
+
+	interval := w.Interval
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+	debounce := w.Debounce
+	if debounce <= 0 {
+		debounce = defaultWatchDebounce
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var pending bool
+	var changedAt time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if current := w.stat(); !sameFileStates(state, current) {
+				state, changedAt, pending = current, now, true
+			} else if pending && now.Sub(changedAt) >= debounce {
+				pending = false
+				w.reload(ctx)
+			}
+		}
+	}
+}
+
+// reload loads the files and applies their values, keeping the previous
+// values when loading fails
+func (w *Watcher) reload(ctx context.Context) error {
+	config := w.Config
+	if config == nil {
+		config = defaultConfig
+	}
+	values, origin, err := loadFirst(config.loadContext(ctx), fileSources(w.Format, w.Files))
+
+	w.mu.Lock()
+	w.err = err
+	if err != nil {
+		w.mu.Unlock()
+		return err
+	}
+	prev := w.values
+	w.values = values
+	subscribers := append([]func(Diff){}, w.subscribers...)
+	w.mu.Unlock()
+
+	diff := config.replace(prev, values, originsOf(values, origin))
+	if prev == nil || diff.Empty() {
+		return nil
+	}
+	for _, fn := range subscribers {
+		fn(diff)
+	}
+	return nil
+}
+
+// fileState identifies a version of a watched file
+type fileState struct {
+	exists  bool
+	size    int64
+	modTime time.Time
+}
+
+// stat returns the current state of every watched file
+func (w *Watcher) stat() []fileState {
+	state := make([]fileState, len(w.Files))
+	for i, file := range w.Files {
+		if info, err := os.Stat(file); err == nil {
+			state[i] = fileState{exists: true, size: info.Size(), modTime: info.ModTime()}
+		}
+	}
+	return state
+}
+
+// sameFileStates reports whether no watched file changed between a and b
+func sameFileStates(a, b []fileState) bool {
+	for i := range a {
+		if a[i].exists != b[i].exists || a[i].size != b[i].size || !a[i].modTime.Equal(b[i].modTime) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,173 @@
+package goenv
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func writeWatched(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func waitDiff(t *testing.T, diffs <-chan Diff) Diff {
+	t.Helper()
+	select {
+	case diff := <-diffs:
+		return diff
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a reload")
+		return Diff{}
+	}
+}
+
+func startWatcher(t *testing.T, w *Watcher) <-chan Diff {
+	t.Helper()
+
+	diffs := make(chan Diff, 8)
+	w.Subscribe(func(d Diff) { diffs <- d })
+	if err := w.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(w.Stop)
+	return diffs
+}
+
+func TestWatcher_Reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.env")
+	writeWatched(t, path, "HOST=localhost\nPORT=8080\nDEBUG=true\n")
+
+	c := NewConfig()
+	c.Set("OTHER", "kept")
+	w := &Watcher{Files: []string{path}, Config: c, Interval: 10 * time.Millisecond, Debounce: 20 * time.Millisecond}
+	diffs := startWatcher(t, w)
+
+	if got := Get(c, "PORT", 0); got != 8080 {
+		t.Fatalf("Get() after Start() = %v, want 8080", got)
+	}
+
+	writeWatched(t, path, "HOST=example.com\nPORT=8080\nLEVEL=debug\n")
+	diff := waitDiff(t, diffs)
+
+	want := Diff{
+		Added:   []string{"LEVEL"},
+		Changed: []string{"HOST"},
+		Removed: []string{"DEBUG"},
+		Old:     map[string]string{"HOST": "localhost", "DEBUG": "true"},
+		New:     map[string]string{"HOST": "example.com", "LEVEL": "debug"},
+	}
+	if !reflect.DeepEqual(diff, want) {
+		t.Errorf("diff = %+v, want %+v", diff, want)
+	}
+	if got := diff.String(); got != "added: LEVEL; changed: HOST; removed: DEBUG" {
+		t.Errorf("String() = %q", got)
+	}
+	if _, ok := c.Lookup("DEBUG"); ok {
+		t.Error("removed key DEBUG is still set")
+	}
+	if got, _ := c.Lookup("OTHER"); got != "kept" {
+		t.Errorf("OTHER = %q, want kept", got)
+	}
+}
+
+func TestWatcher_KeepsLastGoodConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.json")
+	writeWatched(t, path, `{"server": {"port": 8080}}`)
+
+	c := NewConfig()
+	w := &Watcher{Files: []string{path}, Config: c, Interval: 10 * time.Millisecond, Debounce: 20 * time.Millisecond}
+	diffs := startWatcher(t, w)
+
+	writeWatched(t, path, `{"server": {"port": `)
+	deadline := time.Now().Add(5 * time.Second)
+	for w.Err() == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the reload error")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := Get(c, "server.port", 0); got != 8080 {
+		t.Errorf("Get() after a failed reload = %v, want 8080", got)
+	}
+
+	writeWatched(t, path, `{"server": {"port": 9090}}`)
+	if diff := waitDiff(t, diffs); !reflect.DeepEqual(diff.Changed, []string{"server.port"}) {
+		t.Errorf("Changed = %v, want [server.port]", diff.Changed)
+	}
+	if err := w.Err(); err != nil {
+		t.Errorf("Err() after a successful reload = %v", err)
+	}
+	if got := Get(c, "server.port", 0); got != 9090 {
+		t.Errorf("Get() = %v, want 9090", got)
+	}
+}
+
+func TestWatcher_Debounce(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.env")
+	writeWatched(t, path, "A=1\nB=2\n")
+
+	c := NewConfig()
+	w := &Watcher{Files: []string{path}, Config: c, Interval: 10 * time.Millisecond, Debounce: 300 * time.Millisecond}
+	diffs := startWatcher(t, w)
+
+	// An editor that truncates the file before writing the new content
+	writeWatched(t, path, "")
+	time.Sleep(50 * time.Millisecond)
+	writeWatched(t, path, "A=1\nB=3\n")
+
+	diff := waitDiff(t, diffs)
+	if len(diff.Removed) != 0 || !reflect.DeepEqual(diff.Changed, []string{"B"}) {
+		t.Errorf("diff = %v, want only B changed", diff)
+	}
+	select {
+	case diff := <-diffs:
+		t.Errorf("unexpected second reload: %v", diff)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestWatcher_StartError(t *testing.T) {
+	w := &Watcher{Files: []string{filepath.Join(t.TempDir(), "missing.env")}, Config: NewConfig()}
+	if err := w.Start(context.Background()); err == nil {
+		t.Fatal("Start() expected an error for a missing file")
+	}
+	w.Stop()
+}
+
+func TestWatcher_Restart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.env")
+	writeWatched(t, path, "PORT=8080\n")
+	w := &Watcher{Files: []string{path}, Config: NewConfig(), Interval: 10 * time.Millisecond}
+
+	if err := w.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if err := w.Start(context.Background()); err == nil {
+		t.Error("Start() on a running watcher expected an error")
+	}
+	w.Stop()
+	if err := w.Start(context.Background()); err != nil {
+		t.Fatalf("Start() after Stop() error = %v", err)
+	}
+	w.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := w.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	cancel()
+	deadline := time.Now().Add(5 * time.Second)
+	for w.Start(context.Background()) != nil {
+		if time.Now().After(deadline) {
+			t.Fatal("Start() after the context was canceled kept failing")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	w.Stop()
+}